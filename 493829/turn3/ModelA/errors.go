@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind classifies a fetch failure so callers can branch with errors.Is.
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindNotFound
+	KindUnavailable
+	KindInvalid
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not found"
+	case KindUnavailable:
+		return "unavailable"
+	case KindInvalid:
+		return "invalid"
+	default:
+		return "unknown"
+	}
+}
+
+// Sentinel errors for each kind; errors.Is(err, ErrNotFound) matches any
+// *FetchError of that kind.
+var (
+	ErrNotFound    = &FetchError{Kind: KindNotFound}
+	ErrUnavailable = &FetchError{Kind: KindUnavailable}
+	ErrInvalid     = &FetchError{Kind: KindInvalid}
+)
+
+// FetchError replaces the old CustomError string with a structured error.
+type FetchError struct {
+	Kind   Kind
+	Source string
+	Msg    string
+	Err    error
+}
+
+func (e *FetchError) Error() string {
+	msg := e.Kind.String()
+	if e.Source != "" {
+		msg = e.Source + ": " + msg
+	}
+	if e.Msg != "" {
+		msg += ": " + e.Msg
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches another *FetchError with the same Kind, so the package
+// sentinels work with errors.Is regardless of source or message.
+func (e *FetchError) Is(target error) bool {
+	t, ok := target.(*FetchError)
+	if !ok {
+		return false
+	}
+	return t.Kind == e.Kind
+}
+
+// newFetchError builds a *FetchError for source with a formatted message.
+func newFetchError(kind Kind, source string, format string, args ...interface{}) *FetchError {
+	return &FetchError{Kind: kind, Source: source, Msg: fmt.Sprintf(format, args...)}
+}
+
+// KindOf returns the Kind of the first *FetchError in err's chain.
+func KindOf(err error) Kind {
+	var fe *FetchError
+	if errors.As(err, &fe) {
+		return fe.Kind
+	}
+	return KindUnknown
+}
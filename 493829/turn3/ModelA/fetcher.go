@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// User is the value produced by every UserFetcher.
+type User struct {
+	ID   int
+	Name string
+}
+
+// UserFetcher is a pluggable source of users, such as a database or an API.
+type UserFetcher interface {
+	Name() string
+	FetchUser(ctx context.Context, id int) Result[User]
+}
+
+// DBFetcher serves users from an in-memory table standing in for a database.
+type DBFetcher struct {
+	Users   map[int]User
+	Latency time.Duration
+}
+
+func (f *DBFetcher) Name() string { return "db" }
+
+func (f *DBFetcher) FetchUser(ctx context.Context, id int) Result[User] {
+	start := time.Now()
+	md := func(code int) Metadata {
+		return Metadata{Source: f.Name(), FetchedAt: start, Duration: time.Since(start), StatusCode: code}
+	}
+
+	if id <= 0 {
+		return Fail[User](newFetchError(KindInvalid, f.Name(), "id %d must be positive", id), md(400))
+	}
+	select {
+	case <-time.After(f.Latency):
+	case <-ctx.Done():
+		return Fail[User](&FetchError{Kind: KindUnavailable, Source: f.Name(), Err: ctx.Err()}, md(503))
+	}
+	user, ok := f.Users[id]
+	if !ok {
+		return Fail[User](newFetchError(KindNotFound, f.Name(), "user %d", id), md(404))
+	}
+	return Ok(user, md(200))
+}
+
+// APIFetcher simulates a remote API that may be down.
+type APIFetcher struct {
+	Down    bool
+	Latency time.Duration
+}
+
+func (f *APIFetcher) Name() string { return "api" }
+
+func (f *APIFetcher) FetchUser(ctx context.Context, id int) Result[User] {
+	start := time.Now()
+	md := func(code int) Metadata {
+		return Metadata{Source: f.Name(), FetchedAt: start, Duration: time.Since(start), StatusCode: code}
+	}
+
+	select {
+	case <-time.After(f.Latency):
+	case <-ctx.Done():
+		return Fail[User](&FetchError{Kind: KindUnavailable, Source: f.Name(), Err: ctx.Err()}, md(503))
+	}
+	if f.Down {
+		return Fail[User](newFetchError(KindUnavailable, f.Name(), "API call failed"), md(503))
+	}
+	return Ok(User{ID: id, Name: "api-user"}, md(200))
+}
+
+// Aggregate collects the per-source results of a concurrent fetch.
+type Aggregate struct {
+	Results map[string]Result[User]
+}
+
+// First returns the first successful result in the given source order, or a
+// failed Result carrying every source's error if none succeeded.
+func (a Aggregate) First(order ...string) Result[User] {
+	for _, name := range order {
+		if r, ok := a.Results[name]; ok && r.IsOk() {
+			return r
+		}
+	}
+	err := a.Err()
+	if err == nil {
+		err = newFetchError(KindUnavailable, "", "no source answered")
+	}
+	return Fail[User](err, Metadata{})
+}
+
+// Err joins the errors of every failed source, or returns nil.
+func (a Aggregate) Err() error {
+	var errs []error
+	for _, r := range a.Results {
+		if r.Err() != nil {
+			errs = append(errs, r.Err())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fetchData queries all fetchers concurrently and waits for every one of them.
+// Results are keyed by source name, so it panics if two fetchers share one;
+// that is a wiring mistake, not a runtime condition.
+func fetchData(ctx context.Context, id int, fetchers ...UserFetcher) Aggregate {
+	seen := make(map[string]bool, len(fetchers))
+	for _, f := range fetchers {
+		if seen[f.Name()] {
+			panic("fetchData: duplicate fetcher name " + f.Name())
+		}
+		seen[f.Name()] = true
+	}
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		agg = Aggregate{Results: make(map[string]Result[User], len(fetchers))}
+	)
+	for _, f := range fetchers {
+		wg.Add(1)
+		go func(f UserFetcher) {
+			defer wg.Done()
+			r := f.FetchUser(ctx, id)
+			mu.Lock()
+			agg.Results[f.Name()] = r
+			mu.Unlock()
+		}(f)
+	}
+	wg.Wait()
+	return agg
+}
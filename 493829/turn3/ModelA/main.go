@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func main() {
+	db := &DBFetcher{
+		Users:   map[int]User{1: {ID: 1, Name: "Alice"}, 2: {ID: 2, Name: "Bob"}},
+		Latency: 20 * time.Millisecond,
+	}
+	api := &APIFetcher{Down: true, Latency: 10 * time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for _, id := range []int{1, 3, -1} {
+		agg := fetchData(ctx, id, db, api)
+		for _, name := range []string{"db", "api"} {
+			r := agg.Results[name]
+			md := r.Metadata()
+			if err := r.Err(); err != nil {
+				fmt.Printf("[%s] id=%d error (%s, status %d): %v\n", name, id, KindOf(err), md.StatusCode, err)
+				continue
+			}
+			fmt.Printf("[%s] id=%d user=%+v took=%s\n", name, id, r.Value(), md.Duration.Round(time.Millisecond))
+		}
+
+		err := agg.Err()
+		switch {
+		case errors.Is(err, ErrInvalid):
+			fmt.Println("  request was invalid, not retrying")
+		case errors.Is(err, ErrNotFound):
+			fmt.Println("  user does not exist in at least one source")
+		case errors.Is(err, ErrUnavailable):
+			fmt.Println("  a source is unavailable, serving what we have")
+		}
+
+		best := agg.First("db", "api")
+		upper := Map(best, func(u User) string { return strings.ToUpper(u.Name) })
+		fmt.Println("  display name:", upper.OrElse("<anonymous>"))
+
+		greeting := FlatMap(best, func(u User) Result[string] {
+			if u.Name == "" {
+				return Fail[string](newFetchError(KindInvalid, "greeting", "empty name"), best.Metadata())
+			}
+			return Ok("Hello, "+u.Name, best.Metadata())
+		})
+		fmt.Println("  greeting:", greeting.OrElse("Hello, stranger"))
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import "time"
+
+// Metadata describes where and how a Result was produced.
+type Metadata struct {
+	Source     string
+	FetchedAt  time.Time
+	Duration   time.Duration
+	StatusCode int
+}
+
+// Result holds either a value of type T or an error, plus typed metadata.
+type Result[T any] struct {
+	value    T
+	err      error
+	metadata Metadata
+}
+
+// Ok returns a successful Result wrapping value.
+func Ok[T any](value T, md Metadata) Result[T] {
+	return Result[T]{value: value, metadata: md}
+}
+
+// Fail returns a failed Result wrapping err.
+func Fail[T any](err error, md Metadata) Result[T] {
+	return Result[T]{err: err, metadata: md}
+}
+
+func (r Result[T]) Value() T {
+	return r.value
+}
+
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+func (r Result[T]) Metadata() Metadata {
+	return r.metadata
+}
+
+// IsOk reports whether the Result carries a value rather than an error.
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+// Unwrap returns the value and error as a conventional Go pair.
+func (r Result[T]) Unwrap() (T, error) {
+	return r.value, r.err
+}
+
+// OrElse returns the value, or fallback if the Result failed.
+func (r Result[T]) OrElse(fallback T) T {
+	if r.err != nil {
+		return fallback
+	}
+	return r.value
+}
+
+// Map applies fn to the value of a successful Result. Failed results are
+// passed through with their error and metadata untouched.
+func Map[T, U any](r Result[T], fn func(T) U) Result[U] {
+	if r.err != nil {
+		return Fail[U](r.err, r.metadata)
+	}
+	return Ok(fn(r.value), r.metadata)
+}
+
+// FlatMap chains an operation that may itself fail.
+func FlatMap[T, U any](r Result[T], fn func(T) Result[U]) Result[U] {
+	if r.err != nil {
+		return Fail[U](r.err, r.metadata)
+	}
+	return fn(r.value)
+}
@@ -0,0 +1,328 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Address is a parsed and normalized US postal address.
+type Address struct {
+	Number     string
+	Street     string
+	Unit       string
+	City       string
+	State      string
+	Zip        string
+	Zip4       string
+	Confidence float64
+	Issues     []string
+}
+
+// String formats the address on a single line in USPS style.
+func (a Address) String() string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(a.Number + " " + a.Street))
+	if a.Unit != "" {
+		b.WriteString(" " + a.Unit)
+	}
+	fmt.Fprintf(&b, ", %s, %s %s", a.City, a.State, a.Zip)
+	if a.Zip4 != "" {
+		b.WriteString("-" + a.Zip4)
+	}
+	return b.String()
+}
+
+var zipRegex = regexp.MustCompile(`^(\d{5})(?:-(\d{4}))?$`)
+
+// streetSuffixes maps common spellings of street types to USPS abbreviations.
+var streetSuffixes = map[string]string{
+	"STREET": "ST", "ST": "ST", "STR": "ST",
+	"AVENUE": "AVE", "AVE": "AVE", "AV": "AVE",
+	"ROAD": "RD", "RD": "RD",
+	"BOULEVARD": "BLVD", "BLVD": "BLVD",
+	"DRIVE": "DR", "DR": "DR",
+	"LANE": "LN", "LN": "LN",
+	"COURT": "CT", "CT": "CT",
+	"PLACE": "PL", "PL": "PL",
+	"TERRACE": "TER", "TER": "TER",
+	"PARKWAY": "PKWY", "PKWY": "PKWY",
+	"HIGHWAY": "HWY", "HWY": "HWY",
+	"CIRCLE": "CIR", "CIR": "CIR",
+	"WAY":    "WAY",
+	"SQUARE": "SQ", "SQ": "SQ",
+	"TRAIL": "TRL", "TRL": "TRL",
+}
+
+// unitDesignators maps secondary unit words to USPS abbreviations.
+var unitDesignators = map[string]string{
+	"APARTMENT": "APT", "APT": "APT",
+	"SUITE": "STE", "STE": "STE",
+	"UNIT":  "UNIT",
+	"FLOOR": "FL", "FL": "FL",
+	"ROOM": "RM", "RM": "RM",
+	"BUILDING": "BLDG", "BLDG": "BLDG",
+	"#": "#",
+}
+
+var directionals = map[string]string{
+	"NORTH": "N", "N": "N", "SOUTH": "S", "S": "S",
+	"EAST": "E", "E": "E", "WEST": "W", "W": "W",
+	"NORTHEAST": "NE", "NE": "NE", "NORTHWEST": "NW", "NW": "NW",
+	"SOUTHEAST": "SE", "SE": "SE", "SOUTHWEST": "SW", "SW": "SW",
+}
+
+// states maps both full names and abbreviations to the two-letter code.
+var states = map[string]string{}
+
+func init() {
+	names := map[string]string{
+		"AL": "ALABAMA", "AK": "ALASKA", "AZ": "ARIZONA", "AR": "ARKANSAS",
+		"CA": "CALIFORNIA", "CO": "COLORADO", "CT": "CONNECTICUT", "DE": "DELAWARE",
+		"DC": "DISTRICT OF COLUMBIA", "FL": "FLORIDA", "GA": "GEORGIA", "HI": "HAWAII",
+		"ID": "IDAHO", "IL": "ILLINOIS", "IN": "INDIANA", "IA": "IOWA",
+		"KS": "KANSAS", "KY": "KENTUCKY", "LA": "LOUISIANA", "ME": "MAINE",
+		"MD": "MARYLAND", "MA": "MASSACHUSETTS", "MI": "MICHIGAN", "MN": "MINNESOTA",
+		"MS": "MISSISSIPPI", "MO": "MISSOURI", "MT": "MONTANA", "NE": "NEBRASKA",
+		"NV": "NEVADA", "NH": "NEW HAMPSHIRE", "NJ": "NEW JERSEY", "NM": "NEW MEXICO",
+		"NY": "NEW YORK", "NC": "NORTH CAROLINA", "ND": "NORTH DAKOTA", "OH": "OHIO",
+		"OK": "OKLAHOMA", "OR": "OREGON", "PA": "PENNSYLVANIA", "RI": "RHODE ISLAND",
+		"SC": "SOUTH CAROLINA", "SD": "SOUTH DAKOTA", "TN": "TENNESSEE", "TX": "TEXAS",
+		"UT": "UTAH", "VT": "VERMONT", "VA": "VIRGINIA", "WA": "WASHINGTON",
+		"WV": "WEST VIRGINIA", "WI": "WISCONSIN", "WY": "WYOMING", "PR": "PUERTO RICO",
+	}
+	for abbr, name := range names {
+		states[abbr] = abbr
+		states[name] = abbr
+	}
+}
+
+// token is one word of the input, remembering whether a comma followed it.
+type token struct {
+	text  string
+	comma bool
+}
+
+// tokenize upper-cases the input, drops periods and splits on whitespace,
+// keeping comma boundaries because they separate street, city and state.
+func tokenize(input string) []token {
+	var tokens []token
+	for _, field := range strings.Fields(strings.ToUpper(input)) {
+		field = strings.ReplaceAll(field, ".", "")
+		for _, part := range strings.SplitAfter(field, ",") {
+			comma := strings.HasSuffix(part, ",")
+			part = strings.TrimSuffix(part, ",")
+			if part == "" {
+				if comma && len(tokens) > 0 {
+					tokens[len(tokens)-1].comma = true
+				}
+				continue
+			}
+			// "#12" is a designator and a value.
+			if strings.HasPrefix(part, "#") && len(part) > 1 {
+				tokens = append(tokens, token{text: "#"})
+				part = part[1:]
+			}
+			tokens = append(tokens, token{text: part, comma: comma})
+		}
+	}
+	return tokens
+}
+
+// ParseAddress splits a free-form single-line address into its components.
+// It works from both ends: ZIP and state are taken from the tail, street
+// number from the head, and the street suffix or a comma marks where the
+// street ends and the city begins.
+func ParseAddress(input string) Address {
+	var a Address
+	tokens := tokenize(input)
+	issue := func(penalty float64, format string, args ...interface{}) {
+		a.Confidence -= penalty
+		a.Issues = append(a.Issues, fmt.Sprintf(format, args...))
+	}
+	a.Confidence = 1
+
+	if len(tokens) == 0 {
+		issue(1, "empty address")
+		a.Confidence = 0
+		return a
+	}
+
+	// ZIP (+4). Accept "12345 6789" as well as "12345-6789".
+	end := len(tokens)
+	if end > 0 {
+		last := tokens[end-1].text
+		if m := zipRegex.FindStringSubmatch(last); m != nil {
+			a.Zip, a.Zip4 = m[1], m[2]
+			end--
+		} else if end > 1 && len(last) == 4 && isDigits(last) {
+			if m := zipRegex.FindStringSubmatch(tokens[end-2].text); m != nil && m[2] == "" {
+				a.Zip, a.Zip4 = m[1], last
+				end -= 2
+			}
+		}
+	}
+	if a.Zip == "" {
+		issue(0.3, "missing or malformed ZIP code")
+	}
+
+	// State: try multi-word names first ("NEW YORK", "DISTRICT OF COLUMBIA").
+	for n := 3; n >= 1 && a.State == ""; n-- {
+		if end-n < 1 {
+			continue
+		}
+		words := make([]string, 0, n)
+		for _, t := range tokens[end-n : end] {
+			words = append(words, t.text)
+		}
+		if code, ok := states[strings.Join(words, " ")]; ok {
+			// A comma inside the candidate means it spans two fields.
+			if n > 1 && hasComma(tokens[end-n:end-1]) {
+				continue
+			}
+			a.State = code
+			end -= n
+		}
+	}
+	if a.State == "" {
+		issue(0.3, "missing or unknown state")
+	}
+
+	// Street number.
+	start := 0
+	if isStreetNumber(tokens[0].text) {
+		a.Number = tokens[0].text
+		start = 1
+	} else {
+		issue(0.2, "missing street number")
+	}
+
+	// City: everything after the last comma before the state. Without a
+	// comma, fall back to whatever follows the street suffix or unit.
+	cityStart := -1
+	for i := end - 2; i >= start; i-- {
+		if tokens[i].comma {
+			cityStart = i + 1
+			break
+		}
+	}
+
+	streetEnd := end
+	if cityStart >= 0 {
+		streetEnd = cityStart
+	}
+
+	// Walk the street segment: name words, then an optional unit. A unit
+	// may also appear as its own comma-separated field ("..., Suite 456, ...").
+	var street []string
+	suffixSeen := false
+	i := start
+	for ; i < streetEnd; i++ {
+		t := tokens[i]
+		if code, ok := unitDesignators[t.text]; ok && i+1 < streetEnd {
+			a.Unit = formatUnit(code, tokens[i+1].text)
+			i += 2
+			break
+		}
+		if cityStart < 0 && suffixSeen {
+			// No comma: after the suffix only directionals belong to the street.
+			if d, ok := directionals[t.text]; ok {
+				street = append(street, d)
+				continue
+			}
+			break
+		}
+		word := t.text
+		if code, ok := streetSuffixes[word]; ok && len(street) > 0 {
+			word = code
+			suffixSeen = true
+		} else if d, ok := directionals[word]; ok && (len(street) == 0 || suffixSeen) {
+			word = d
+		}
+		street = append(street, word)
+		if t.comma {
+			i++
+			break
+		}
+	}
+	// A unit field between the street and the city.
+	if a.Unit == "" && i < streetEnd {
+		if code, ok := unitDesignators[tokens[i].text]; ok && i+1 < streetEnd {
+			a.Unit = formatUnit(code, tokens[i+1].text)
+			i += 2
+		}
+	}
+	if cityStart < 0 {
+		cityStart = i
+		if cityStart < end {
+			issue(0.1, "city inferred without comma separator")
+		}
+	} else if i < cityStart {
+		var extra []string
+		for _, t := range tokens[i:cityStart] {
+			extra = append(extra, t.text)
+		}
+		issue(0.15, "unrecognized segment %q", strings.Join(extra, " "))
+	}
+
+	a.Street = strings.Join(street, " ")
+	if a.Street == "" {
+		issue(0.3, "missing street name")
+	} else if !suffixSeen {
+		issue(0.05, "street has no recognized suffix")
+	}
+
+	var city []string
+	for _, t := range tokens[cityStart:end] {
+		city = append(city, t.text)
+	}
+	a.City = strings.Join(city, " ")
+	if a.City == "" {
+		issue(0.3, "missing city")
+	}
+
+	if a.Confidence < 0 {
+		a.Confidence = 0
+	}
+	return a
+}
+
+func hasComma(tokens []token) bool {
+	for _, t := range tokens {
+		if t.comma {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isStreetNumber accepts "123", "123A" and ranges such as "12-14".
+func isStreetNumber(s string) bool {
+	if s == "" || !unicode.IsDigit(rune(s[0])) {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsDigit(r) && r != '-' && !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return len(s) <= 8
+}
+
+// formatUnit renders a secondary unit, writing "#12" rather than "# 12".
+func formatUnit(designator, value string) string {
+	if designator == "#" {
+		return "#" + value
+	}
+	return designator + " " + value
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// job is a CSV row waiting to be parsed.
+type job struct {
+	line int
+	id   string
+	raw  string
+}
+
+var wg sync.WaitGroup
+
+func main() {
+	input := flag.String("in", "addresses.csv", "CSV file with id,address columns")
+	workers := flag.Int("workers", 4, "number of parser workers")
+	minConfidence := flag.Float64("min-confidence", 0.7, "reject addresses scoring below this")
+	flag.Parse()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go handleShutdown(sigChan, cancel)
+
+	f, err := os.Open(*input)
+	if err != nil {
+		log.Fatalf("Error opening input: %v", err)
+	}
+	defer f.Close()
+
+	jobs := make(chan job)
+	results := make(chan Record)
+
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go worker(ctx, i, *minConfidence, jobs, results)
+	}
+
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(jobs)
+		readErr <- readCSV(ctx, f, jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var records []Record
+	for r := range results {
+		records = append(records, r)
+	}
+	if err := <-readErr; err != nil {
+		log.Fatalf("Error reading input: %v", err)
+	}
+
+	// Workers finish out of order; report in file order.
+	sort.Slice(records, func(i, j int) bool { return records[i].Line < records[j].Line })
+	fmt.Print(generateReport(records))
+}
+
+func handleShutdown(sigChan <-chan os.Signal, cancel context.CancelFunc) {
+	<-sigChan
+	log.Println("Shutdown signal received. Canceling context...")
+	cancel()
+}
+
+// readCSV streams rows into jobs. The first row is treated as a header if
+// its second column is literally "address".
+func readCSV(ctx context.Context, r io.Reader, jobs chan<- job) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line, _ := cr.FieldPos(0)
+		if line == 1 && len(row) > 1 && strings.EqualFold(strings.TrimSpace(row[1]), "address") {
+			continue
+		}
+		j := job{line: line}
+		switch len(row) {
+		case 0:
+			continue
+		case 1:
+			j.id, j.raw = fmt.Sprint(line), row[0]
+		default:
+			j.id, j.raw = row[0], row[1]
+		}
+		select {
+		case jobs <- j:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func worker(ctx context.Context, id int, minConfidence float64, jobs <-chan job, results chan<- Record) {
+	defer wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Worker %d: Shutting down...\n", id)
+			return
+		case j, ok := <-jobs:
+			if !ok {
+				return
+			}
+			rec := Record{Line: j.line, ID: j.id, Raw: j.raw, Address: ParseAddress(cleanData(j.raw))}
+			if rec.Address.Confidence < minConfidence {
+				rec.Reject = fmt.Sprintf("confidence %.2f: %s", rec.Address.Confidence, strings.Join(rec.Address.Issues, "; "))
+			}
+			select {
+			case results <- rec:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// cleanData trims the input and collapses runs of whitespace.
+func cleanData(input string) string {
+	return strings.Join(strings.Fields(input), " ")
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Record is one CSV row after parsing.
+type Record struct {
+	Line    int
+	ID      string
+	Raw     string
+	Address Address
+	Reject  string
+}
+
+// stateStats aggregates accepted addresses for one state.
+type stateStats struct {
+	count      int
+	confidence float64
+	cities     map[string]int
+}
+
+// generateReport summarizes a batch: totals, per-state aggregates and the
+// rejected rows with the reason each was rejected.
+func generateReport(records []Record) string {
+	byState := make(map[string]*stateStats)
+	var accepted, rejected []Record
+	for _, r := range records {
+		if r.Reject != "" {
+			rejected = append(rejected, r)
+			continue
+		}
+		accepted = append(accepted, r)
+		s := byState[r.Address.State]
+		if s == nil {
+			s = &stateStats{cities: make(map[string]int)}
+			byState[r.Address.State] = s
+		}
+		s.count++
+		s.confidence += r.Address.Confidence
+		s.cities[r.Address.City]++
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Address report: %d rows, %d accepted, %d rejected\n\n", len(records), len(accepted), len(rejected))
+
+	b.WriteString("Accepted:\n")
+	for _, r := range accepted {
+		fmt.Fprintf(&b, "  %-6s %-50s (%.2f)\n", r.ID, r.Address, r.Address.Confidence)
+	}
+
+	codes := make([]string, 0, len(byState))
+	for code := range byState {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	b.WriteString("\nBy state:\n")
+	fmt.Fprintf(&b, "  %-5s %5s %8s  %s\n", "STATE", "COUNT", "AVG CONF", "TOP CITY")
+	for _, code := range codes {
+		s := byState[code]
+		fmt.Fprintf(&b, "  %-5s %5d %8.2f  %s\n", code, s.count, s.confidence/float64(s.count), topCity(s.cities))
+	}
+
+	if len(rejected) > 0 {
+		b.WriteString("\nRejected:\n")
+		for _, r := range rejected {
+			fmt.Fprintf(&b, "  line %d (%s): %q: %s\n", r.Line, r.ID, r.Raw, r.Reject)
+		}
+	}
+	return b.String()
+}
+
+func topCity(cities map[string]int) string {
+	best, n := "", 0
+	for city, c := range cities {
+		if c > n || (c == n && city < best) {
+			best, n = city, c
+		}
+	}
+	return best
+}
@@ -0,0 +1,205 @@
+package lifecycle
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type recordingCloser struct {
+	name  string
+	order *[]string
+	err   error
+}
+
+func (c recordingCloser) Close() error {
+	*c.order = append(*c.order, c.name)
+	return c.err
+}
+
+func TestGroupClosesInLIFOOrderAndJoinsErrors(t *testing.T) {
+	var order []string
+	errFile := errors.New("file busy")
+	errBody := errors.New("body reset")
+
+	var g Group
+	g.Add("db", recordingCloser{name: "db", order: &order})
+	g.Add("file", recordingCloser{name: "file", order: &order, err: errFile})
+	g.Add("body", recordingCloser{name: "body", order: &order, err: errBody})
+
+	err := g.Close()
+	if got, want := strings.Join(order, ","), "body,file,db"; got != want {
+		t.Errorf("close order = %s, want %s", got, want)
+	}
+	if !errors.Is(err, errFile) || !errors.Is(err, errBody) {
+		t.Errorf("Close() error = %v, want both close errors", err)
+	}
+	if err := g.Close(); err != nil {
+		t.Errorf("second Close() = %v, want nil", err)
+	}
+	if len(order) != 3 {
+		t.Errorf("second Close() re-ran closers: %v", order)
+	}
+}
+
+func TestGroupCloseInto(t *testing.T) {
+	errWork := errors.New("work failed")
+	errClose := errors.New("close failed")
+
+	run := func() (err error) {
+		var g Group
+		defer g.CloseInto(&err)
+		g.AddFunc("res", func() error { return errClose })
+		return errWork
+	}
+
+	err := run()
+	if !errors.Is(err, errWork) || !errors.Is(err, errClose) {
+		t.Errorf("run() = %v, want work and close errors", err)
+	}
+}
+
+type countingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *countingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestDrainAndClose(t *testing.T) {
+	body := &countingBody{Reader: strings.NewReader("leftover bytes")}
+	if err := DrainAndClose(body, 1<<10); err != nil {
+		t.Fatalf("DrainAndClose() = %v", err)
+	}
+	if !body.closed {
+		t.Error("body was not closed")
+	}
+	if n, _ := body.Read(make([]byte, 1)); n != 0 {
+		t.Error("body was not drained")
+	}
+}
+
+func TestWithTx(t *testing.T) {
+	errWork := errors.New("insert failed")
+	errCommit := errors.New("serialization failure")
+
+	tests := []struct {
+		name          string
+		fnErr         error
+		commitErr     error
+		wantErr       error
+		wantCommits   int
+		wantRollbacks int
+	}{
+		{name: "commit on success", wantCommits: 1},
+		{name: "rollback on error", fnErr: errWork, wantErr: errWork, wantRollbacks: 1},
+		{name: "commit failure surfaces", commitErr: errCommit, wantErr: errCommit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, stub := newStubDB(t.Name())
+			defer db.Close()
+			stub.commitErr = tt.commitErr
+
+			err := WithTx(context.Background(), db, nil, func(tx *sql.Tx) error {
+				if _, err := tx.Exec("INSERT INTO users (name) VALUES (?)", "John Doe"); err != nil {
+					return err
+				}
+				return tt.fnErr
+			})
+
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("WithTx() = %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WithTx() = %v, want %v", err, tt.wantErr)
+			}
+			if stub.commits != tt.wantCommits || stub.rollbacks != tt.wantRollbacks {
+				t.Errorf("commits=%d rollbacks=%d, want %d and %d", stub.commits, stub.rollbacks, tt.wantCommits, tt.wantRollbacks)
+			}
+		})
+	}
+}
+
+func TestWithTxRollsBackOnPanic(t *testing.T) {
+	db, stub := newStubDB(t.Name())
+	defer db.Close()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("panic was swallowed")
+		}
+		if stub.rollbacks != 1 || stub.commits != 0 {
+			t.Errorf("commits=%d rollbacks=%d, want a single rollback", stub.commits, stub.rollbacks)
+		}
+	}()
+	_ = WithTx(context.Background(), db, nil, func(tx *sql.Tx) error {
+		panic("boom")
+	})
+}
+
+type Audit struct {
+	CreatedBy string `db:"created_by"`
+}
+
+type Data struct {
+	Audit
+	ID     int64
+	Name   string `db:"full_name"`
+	Secret string `db:"-"`
+}
+
+func TestScanAll(t *testing.T) {
+	db, stub := newStubDB(t.Name())
+	defer db.Close()
+	stub.setResult("SELECT * FROM data",
+		[]string{"id", "full_name", "created_by", "unused"},
+		[]driver.Value{int64(1), "Alice", "admin", "x"},
+		[]driver.Value{int64(2), "Bob", "ops", "y"},
+	)
+
+	rows, err := db.Query("SELECT * FROM data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ScanAll[Data](rows)
+	if err != nil {
+		t.Fatalf("ScanAll() = %v", err)
+	}
+	want := []Data{
+		{ID: 1, Name: "Alice", Audit: Audit{CreatedBy: "admin"}},
+		{ID: 2, Name: "Bob", Audit: Audit{CreatedBy: "ops"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ScanAll() returned %d rows, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("row %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestScanStructRejectsNonPointer(t *testing.T) {
+	db, stub := newStubDB(t.Name())
+	defer db.Close()
+	stub.setResult("SELECT 1", []string{"id"}, []driver.Value{int64(1)})
+
+	rows, err := db.Query("SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	rows.Next()
+	if err := ScanStruct(rows, Data{}); err == nil {
+		t.Error("ScanStruct(non-pointer) = nil, want error")
+	}
+}
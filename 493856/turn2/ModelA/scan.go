@@ -0,0 +1,80 @@
+package lifecycle
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ScanStruct scans the current row of rows into the struct pointed to by
+// dest, matching columns to fields by their `db` tag or, failing that, by
+// case-insensitive field name. Columns with no matching field are skipped.
+func ScanStruct(rows *sql.Rows, dest interface{}) error {
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("lifecycle: ScanStruct needs a non-nil struct pointer, got %T", dest)
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	fields := fieldIndex(v.Elem().Type())
+	targets := make([]interface{}, len(columns))
+	for i, col := range columns {
+		idx, ok := fields[strings.ToLower(col)]
+		if !ok {
+			targets[i] = new(interface{})
+			continue
+		}
+		targets[i] = v.Elem().FieldByIndex(idx).Addr().Interface()
+	}
+	return rows.Scan(targets...)
+}
+
+// ScanAll scans every remaining row into a slice of T and closes rows.
+func ScanAll[T any](rows *sql.Rows) (out []T, err error) {
+	defer func() {
+		err = errors.Join(err, rows.Close())
+	}()
+	for rows.Next() {
+		var item T
+		if err := ScanStruct(rows, &item); err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
+// fieldIndex maps lower-cased column names to struct field indexes,
+// descending into embedded structs.
+func fieldIndex(t reflect.Type) map[string][]int {
+	out := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && tag == "" {
+			for name, idx := range fieldIndex(f.Type) {
+				if _, exists := out[name]; !exists {
+					out[name] = append([]int{i}, idx...)
+				}
+			}
+			continue
+		}
+		name := tag
+		if name == "" {
+			name = f.Name
+		}
+		out[strings.ToLower(name)] = []int{i}
+	}
+	return out
+}
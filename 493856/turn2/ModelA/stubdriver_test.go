@@ -0,0 +1,146 @@
+package lifecycle
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// stubDriver is an in-memory database/sql driver. Each DSN names a stubDB
+// registered with newStubDB; queries return canned result sets and
+// transactions only record whether they were committed or rolled back.
+type stubDriver struct{}
+
+var (
+	stubMu  sync.Mutex
+	stubDBs = map[string]*stubDB{}
+)
+
+func init() {
+	sql.Register("lifecyclestub", stubDriver{})
+}
+
+type stubDB struct {
+	mu          sync.Mutex
+	results     map[string]stubResult
+	execs       []string
+	commits     int
+	rollbacks   int
+	commitErr   error
+	rollbackErr error
+}
+
+type stubResult struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+// newStubDB registers a fresh stubDB under name and opens it.
+func newStubDB(name string) (*sql.DB, *stubDB) {
+	s := &stubDB{results: map[string]stubResult{}}
+	stubMu.Lock()
+	stubDBs[name] = s
+	stubMu.Unlock()
+	db, err := sql.Open("lifecyclestub", name)
+	if err != nil {
+		panic(err)
+	}
+	return db, s
+}
+
+func (s *stubDB) setResult(query string, columns []string, rows ...[]driver.Value) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[query] = stubResult{columns: columns, rows: rows}
+}
+
+func (stubDriver) Open(name string) (driver.Conn, error) {
+	stubMu.Lock()
+	defer stubMu.Unlock()
+	s, ok := stubDBs[name]
+	if !ok {
+		return nil, fmt.Errorf("stub: unknown database %q", name)
+	}
+	return &stubConn{db: s}, nil
+}
+
+type stubConn struct {
+	db *stubDB
+}
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) {
+	return &stubStmt{db: c.db, query: query}, nil
+}
+
+func (c *stubConn) Close() error { return nil }
+
+func (c *stubConn) Begin() (driver.Tx, error) {
+	return &stubTx{db: c.db}, nil
+}
+
+type stubTx struct {
+	db *stubDB
+}
+
+func (t *stubTx) Commit() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	if t.db.commitErr != nil {
+		return t.db.commitErr
+	}
+	t.db.commits++
+	return nil
+}
+
+func (t *stubTx) Rollback() error {
+	t.db.mu.Lock()
+	defer t.db.mu.Unlock()
+	t.db.rollbacks++
+	return t.db.rollbackErr
+}
+
+type stubStmt struct {
+	db    *stubDB
+	query string
+}
+
+func (s *stubStmt) Close() error  { return nil }
+func (s *stubStmt) NumInput() int { return -1 }
+
+func (s *stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.execs = append(s.db.execs, s.query)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	res, ok := s.db.results[s.query]
+	if !ok {
+		return nil, errors.New("stub: no result for " + s.query)
+	}
+	return &stubRows{columns: res.columns, rows: res.rows}, nil
+}
+
+type stubRows struct {
+	columns []string
+	rows    [][]driver.Value
+	pos     int
+}
+
+func (r *stubRows) Columns() []string { return r.columns }
+func (r *stubRows) Close() error      { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.pos])
+	r.pos++
+	return nil
+}
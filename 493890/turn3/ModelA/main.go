@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+var db *sql.DB
+
+// ErrInsufficientFunds aborts a transfer; it is not retried.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var err error
+	db, err = sql.Open("memdb", "bank")
+	if err != nil {
+		log.Fatalf("Error connecting to database: %v", err)
+	}
+	defer db.Close()
+
+	accounts := []string{"alice", "bob", "carol"}
+	for _, id := range accounts {
+		if _, err := db.ExecContext(ctx, stmtInsertAccount, id, 100); err != nil {
+			log.Fatalf("Error seeding %s: %v", id, err)
+		}
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	go handleShutdown(sigChan, cancel)
+
+	jobs := make(chan Job)
+	pool := &Pool{DB: db, Workers: 4, MaxRetries: 5, Backoff: time.Millisecond}
+	results := pool.Start(ctx, jobs)
+
+	go func() {
+		defer close(jobs)
+		for i := 0; i < 30; i++ {
+			from, to := accounts[i%3], accounts[(i+1)%3]
+			job := Job{ID: i, Run: transfer(from, to, int64(10+i))}
+			select {
+			case jobs <- job:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var committed, failed, retries int
+	for res := range results {
+		retries += res.Attempts - 1
+		if res.Committed {
+			committed++
+			continue
+		}
+		failed++
+		log.Printf("Job %d (worker %d): failed after %d attempt(s): %v\n", res.JobID, res.Worker, res.Attempts, res.Err)
+	}
+	fmt.Printf("Committed %d, failed %d, retried %d time(s)\n", committed, failed, retries)
+
+	balances, err := queryBalances(ctx, db)
+	if err != nil {
+		log.Fatalf("Error reading balances: %v", err)
+	}
+	var total int64
+	for _, id := range accounts {
+		fmt.Printf("  %-6s %4d\n", id, balances[id])
+		total += balances[id]
+	}
+	fmt.Printf("  total  %4d\n", total)
+}
+
+func handleShutdown(sigChan <-chan os.Signal, cancel context.CancelFunc) {
+	<-sigChan
+	log.Println("Shutdown signal received. Canceling context...")
+	cancel()
+}
+
+// transfer returns a job body moving amount between two accounts.
+func transfer(from, to string, amount int64) func(context.Context, *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		var fromBalance, toBalance int64
+		if err := tx.QueryRowContext(ctx, stmtSelectBalance, from).Scan(&fromBalance); err != nil {
+			return fmt.Errorf("read %s: %w", from, err)
+		}
+		if fromBalance < amount {
+			return fmt.Errorf("%s has %d, needs %d: %w", from, fromBalance, amount, ErrInsufficientFunds)
+		}
+		if err := tx.QueryRowContext(ctx, stmtSelectBalance, to).Scan(&toBalance); err != nil {
+			return fmt.Errorf("read %s: %w", to, err)
+		}
+
+		// Simulate processing so concurrent transfers overlap.
+		time.Sleep(time.Millisecond)
+
+		if _, err := tx.ExecContext(ctx, stmtUpdateBalance, fromBalance-amount, from); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, stmtUpdateBalance, toBalance+amount, to)
+		return err
+	}
+}
+
+func queryBalances(ctx context.Context, db *sql.DB) (map[string]int64, error) {
+	rows, err := db.QueryContext(ctx, stmtSelectAll)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[string]int64)
+	for rows.Next() {
+		var id string
+		var balance int64
+		if err := rows.Scan(&id, &balance); err != nil {
+			return nil, err
+		}
+		balances[id] = balance
+	}
+	return balances, rows.Err()
+}
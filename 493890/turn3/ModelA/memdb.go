@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// memdb is a tiny in-memory database/sql driver holding a single accounts
+// table. Transactions use optimistic concurrency: every key a transaction
+// reads or writes is version-checked at commit time, and a conflicting
+// commit fails with ErrSerialization, much like PostgreSQL's SQLSTATE 40001.
+//
+// Only the statements below are understood; anything else is an error.
+const (
+	stmtSelectBalance = "SELECT balance FROM accounts WHERE id = ?"
+	stmtSelectAll     = "SELECT id, balance FROM accounts ORDER BY id"
+	stmtUpdateBalance = "UPDATE accounts SET balance = ? WHERE id = ?"
+	stmtInsertAccount = "INSERT INTO accounts (id, balance) VALUES (?, ?)"
+)
+
+// ErrSerialization is returned by Commit when another transaction changed
+// a row this one depended on. The transaction is safe to retry.
+var ErrSerialization = errors.New("memdb: could not serialize access due to concurrent update")
+
+func init() {
+	sql.Register("memdb", &memDriver{stores: make(map[string]*store)})
+}
+
+type memDriver struct {
+	mu     sync.Mutex
+	stores map[string]*store
+}
+
+// Open returns a connection to the store named by dsn, creating it on first
+// use. Every connection with the same dsn shares one store.
+func (d *memDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s, ok := d.stores[dsn]
+	if !ok {
+		s = &store{rows: make(map[string]record)}
+		d.stores[dsn] = s
+	}
+	return &memConn{store: s}, nil
+}
+
+type record struct {
+	balance int64
+	version uint64
+}
+
+type store struct {
+	mu   sync.Mutex
+	rows map[string]record
+}
+
+type memConn struct {
+	store *store
+	tx    *memTx
+}
+
+func (c *memConn) Prepare(query string) (driver.Stmt, error) {
+	query = strings.Join(strings.Fields(query), " ")
+	switch query {
+	case stmtSelectBalance, stmtSelectAll, stmtUpdateBalance, stmtInsertAccount:
+		return &memStmt{conn: c, query: query}, nil
+	}
+	return nil, fmt.Errorf("memdb: unsupported statement %q", query)
+}
+
+func (c *memConn) Close() error {
+	return nil
+}
+
+func (c *memConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *memConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if c.tx != nil {
+		return nil, errors.New("memdb: transaction already in progress")
+	}
+	c.tx = &memTx{
+		conn:   c,
+		reads:  make(map[string]uint64),
+		writes: make(map[string]int64),
+	}
+	return c.tx, nil
+}
+
+// memTx buffers writes and remembers the version of every row it saw.
+type memTx struct {
+	conn   *memConn
+	reads  map[string]uint64
+	writes map[string]int64
+}
+
+func (t *memTx) Commit() error {
+	defer func() { t.conn.tx = nil }()
+
+	s := t.conn.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, seen := range t.reads {
+		if s.rows[id].version != seen {
+			return ErrSerialization
+		}
+	}
+	for id, balance := range t.writes {
+		r := s.rows[id]
+		s.rows[id] = record{balance: balance, version: r.version + 1}
+	}
+	return nil
+}
+
+func (t *memTx) Rollback() error {
+	t.conn.tx = nil
+	return nil
+}
+
+// lookup reads id through the transaction, if any, recording its version.
+// The caller must hold the store lock.
+func (c *memConn) lookup(id string) (int64, bool) {
+	r, ok := c.store.rows[id]
+	if c.tx == nil {
+		return r.balance, ok
+	}
+	if _, seen := c.tx.reads[id]; !seen {
+		c.tx.reads[id] = r.version
+	}
+	if b, written := c.tx.writes[id]; written {
+		return b, true
+	}
+	return r.balance, ok
+}
+
+// write stores balance for id, buffered if a transaction is open.
+// The caller must hold the store lock.
+func (c *memConn) write(id string, balance int64) {
+	if c.tx != nil {
+		c.tx.writes[id] = balance
+		return
+	}
+	r := c.store.rows[id]
+	c.store.rows[id] = record{balance: balance, version: r.version + 1}
+}
+
+type memStmt struct {
+	conn  *memConn
+	query string
+}
+
+func (s *memStmt) Close() error {
+	return nil
+}
+
+func (s *memStmt) NumInput() int {
+	return strings.Count(s.query, "?")
+}
+
+func (s *memStmt) Exec(args []driver.Value) (driver.Result, error) {
+	st := s.conn.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	switch s.query {
+	case stmtUpdateBalance:
+		balance, id, err := balanceAndID(args[0], args[1])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.conn.lookup(id); !ok {
+			return driver.RowsAffected(0), nil
+		}
+		s.conn.write(id, balance)
+		return driver.RowsAffected(1), nil
+	case stmtInsertAccount:
+		balance, id, err := balanceAndID(args[1], args[0])
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := s.conn.lookup(id); ok {
+			return nil, fmt.Errorf("memdb: duplicate key %q", id)
+		}
+		s.conn.write(id, balance)
+		return driver.RowsAffected(1), nil
+	}
+	return nil, fmt.Errorf("memdb: %q is not an Exec statement", s.query)
+}
+
+func (s *memStmt) Query(args []driver.Value) (driver.Rows, error) {
+	st := s.conn.store
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	switch s.query {
+	case stmtSelectBalance:
+		id, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("memdb: id must be a string, got %T", args[0])
+		}
+		rows := &memRows{columns: []string{"balance"}}
+		if balance, ok := s.conn.lookup(id); ok {
+			rows.values = append(rows.values, []driver.Value{balance})
+		}
+		return rows, nil
+	case stmtSelectAll:
+		ids := make(map[string]bool)
+		for id := range st.rows {
+			ids[id] = true
+		}
+		if s.conn.tx != nil {
+			for id := range s.conn.tx.writes {
+				ids[id] = true
+			}
+		}
+		sorted := make([]string, 0, len(ids))
+		for id := range ids {
+			sorted = append(sorted, id)
+		}
+		sort.Strings(sorted)
+		rows := &memRows{columns: []string{"id", "balance"}}
+		for _, id := range sorted {
+			balance, _ := s.conn.lookup(id)
+			rows.values = append(rows.values, []driver.Value{id, balance})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("memdb: %q is not a Query statement", s.query)
+}
+
+func balanceAndID(balanceArg, idArg driver.Value) (int64, string, error) {
+	balance, ok := balanceArg.(int64)
+	if !ok {
+		return 0, "", fmt.Errorf("memdb: balance must be an integer, got %T", balanceArg)
+	}
+	id, ok := idArg.(string)
+	if !ok {
+		return 0, "", fmt.Errorf("memdb: id must be a string, got %T", idArg)
+	}
+	return balance, id, nil
+}
+
+type memRows struct {
+	columns []string
+	values  [][]driver.Value
+	pos     int
+}
+
+func (r *memRows) Columns() []string {
+	return r.columns
+}
+
+func (r *memRows) Close() error {
+	return nil
+}
+
+func (r *memRows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.pos])
+	r.pos++
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func openTestDB(t *testing.T, balances map[string]int64) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("memdb", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	for id, balance := range balances {
+		if _, err := db.Exec(stmtInsertAccount, id, balance); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func runJobs(t *testing.T, pool *Pool, jobs ...Job) []JobResult {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	queue := make(chan Job, len(jobs))
+	for _, j := range jobs {
+		queue <- j
+	}
+	close(queue)
+
+	var out []JobResult
+	for res := range pool.Start(ctx, queue) {
+		out = append(out, res)
+	}
+	if len(out) != len(jobs) {
+		t.Fatalf("got %d results, want %d", len(out), len(jobs))
+	}
+	return out
+}
+
+func TestCommitDetectsSerializationConflict(t *testing.T) {
+	db := openTestDB(t, map[string]int64{"alice": 100})
+	ctx := context.Background()
+
+	tx1, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx2, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tx := range []*sql.Tx{tx1, tx2} {
+		var balance int64
+		if err := tx.QueryRow(stmtSelectBalance, "alice").Scan(&balance); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.Exec(stmtUpdateBalance, balance+10, "alice"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := tx1.Commit(); err != nil {
+		t.Fatalf("first commit = %v", err)
+	}
+	if err := tx2.Commit(); !errors.Is(err, ErrSerialization) {
+		t.Fatalf("second commit = %v, want ErrSerialization", err)
+	}
+
+	balances, err := queryBalances(ctx, db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balances["alice"] != 110 {
+		t.Errorf("alice = %d, want 110", balances["alice"])
+	}
+}
+
+func TestPoolRetriesSerializationFailure(t *testing.T) {
+	db := openTestDB(t, map[string]int64{"alice": 100, "bob": 0})
+	pool := &Pool{DB: db, Workers: 1, MaxRetries: 3, Backoff: time.Millisecond}
+
+	var calls int32
+	move := transfer("alice", "bob", 30)
+	job := Job{ID: 1, Run: func(ctx context.Context, tx *sql.Tx) error {
+		if err := move(ctx, tx); err != nil {
+			return err
+		}
+		// On the first attempt, change alice behind the transaction's back.
+		if atomic.AddInt32(&calls, 1) == 1 {
+			if _, err := db.Exec(stmtUpdateBalance, 90, "alice"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}}
+
+	res := runJobs(t, pool, job)[0]
+	if !res.Committed || res.Attempts != 2 {
+		t.Fatalf("result = %+v, want committed on attempt 2", res)
+	}
+	balances, _ := queryBalances(context.Background(), db)
+	if balances["alice"] != 60 || balances["bob"] != 30 {
+		t.Errorf("balances = %v, want alice=60 bob=30", balances)
+	}
+}
+
+func TestPoolRollsBackNonRetryableError(t *testing.T) {
+	db := openTestDB(t, map[string]int64{"alice": 5, "bob": 0})
+	pool := &Pool{DB: db, Workers: 1, MaxRetries: 3}
+
+	res := runJobs(t, pool, Job{ID: 1, Run: transfer("alice", "bob", 50)})[0]
+	if res.Committed || res.Attempts != 1 || !errors.Is(res.Err, ErrInsufficientFunds) {
+		t.Fatalf("result = %+v, want a single failed attempt", res)
+	}
+	balances, _ := queryBalances(context.Background(), db)
+	if balances["alice"] != 5 || balances["bob"] != 0 {
+		t.Errorf("balances = %v, want unchanged", balances)
+	}
+}
+
+func TestPoolConcurrentTransfersConserveTotal(t *testing.T) {
+	accounts := []string{"a", "b", "c", "d"}
+	db := openTestDB(t, map[string]int64{"a": 1000, "b": 1000, "c": 1000, "d": 1000})
+	pool := &Pool{DB: db, Workers: 8, MaxRetries: 100, Backoff: 100 * time.Microsecond}
+
+	var jobs []Job
+	for i := 0; i < 40; i++ {
+		jobs = append(jobs, Job{ID: i, Run: transfer(accounts[i%4], accounts[(i+1)%4], int64(i))})
+	}
+	for _, res := range runJobs(t, pool, jobs...) {
+		if !res.Committed {
+			t.Errorf("job %d failed: %v", res.JobID, res.Err)
+		}
+	}
+
+	balances, _ := queryBalances(context.Background(), db)
+	var total int64
+	for _, b := range balances {
+		total += b
+	}
+	if total != 4000 {
+		t.Errorf("total = %d, want 4000 (balances %v)", total, balances)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is one unit of transactional work. Run may be called several times
+// if its transaction hits a serialization failure, so it must not have
+// side effects outside tx.
+type Job struct {
+	ID  int
+	Run func(ctx context.Context, tx *sql.Tx) error
+}
+
+// JobResult reports how a job finished.
+type JobResult struct {
+	JobID     int
+	Worker    int
+	Attempts  int
+	Committed bool
+	Err       error
+}
+
+// Pool runs queued jobs on a fixed set of workers, each job in its own
+// transaction.
+type Pool struct {
+	DB         *sql.DB
+	Workers    int
+	MaxRetries int
+	Backoff    time.Duration
+
+	wg sync.WaitGroup
+}
+
+// Start launches the workers. They stop when jobs is closed and drained or
+// when ctx is cancelled; results is closed once every worker has exited.
+func (p *Pool) Start(ctx context.Context, jobs <-chan Job) <-chan JobResult {
+	results := make(chan JobResult)
+	for i := 0; i < p.Workers; i++ {
+		p.wg.Add(1)
+		go p.worker(ctx, i, jobs, results)
+	}
+	go func() {
+		p.wg.Wait()
+		close(results)
+	}()
+	return results
+}
+
+func (p *Pool) worker(ctx context.Context, id int, jobs <-chan Job, results chan<- JobResult) {
+	defer p.wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("Worker %d: Shutting down: %v\n", id, ctx.Err())
+			return
+		case job, ok := <-jobs:
+			if !ok {
+				return
+			}
+			res := p.runJob(ctx, job)
+			res.Worker = id
+			select {
+			case results <- res:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// runJob executes job in a fresh transaction, retrying with linear backoff
+// while the failure is a serialization conflict.
+func (p *Pool) runJob(ctx context.Context, job Job) JobResult {
+	res := JobResult{JobID: job.ID}
+	for {
+		res.Attempts++
+		err := p.runOnce(ctx, job)
+		if err == nil {
+			res.Committed = true
+			return res
+		}
+		if !errors.Is(err, ErrSerialization) || res.Attempts > p.MaxRetries {
+			res.Err = err
+			return res
+		}
+		select {
+		case <-time.After(p.Backoff * time.Duration(res.Attempts)):
+		case <-ctx.Done():
+			res.Err = errors.Join(err, ctx.Err())
+			return res
+		}
+	}
+}
+
+// runOnce commits if job.Run succeeds and rolls back otherwise. The
+// rollback happens before runOnce returns, not at the end of the worker.
+func (p *Pool) runOnce(ctx context.Context, job Job) (err error) {
+	tx, err := p.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := job.Run(ctx, tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("rollback: %w", rbErr))
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
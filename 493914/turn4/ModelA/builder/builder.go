@@ -0,0 +1,167 @@
+// Package builder is a small generic functional-options core. A Builder
+// records options and validation rules; each Build applies them to a fresh
+// value, so builders can be shared, cloned into variants, and reused
+// without one build leaking into another.
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Option mutates the value under construction.
+type Option[T any] func(*T)
+
+// Rule validates a fully built value. Rules see every field at once, so
+// they can express cross-field constraints.
+type Rule[T any] func(T) error
+
+// FieldError describes one invalid field.
+type FieldError struct {
+	Field string
+	Msg   string
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Msg
+}
+
+// Errorf returns a *FieldError for field.
+func Errorf(field, format string, args ...interface{}) *FieldError {
+	return &FieldError{Field: field, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Builder is immutable: With, Validate and Clone all return a new Builder.
+type Builder[T any] struct {
+	opts  []Option[T]
+	rules []Rule[T]
+	clone func(T) T
+}
+
+// New returns a Builder that validates with rules.
+func New[T any](rules ...Rule[T]) *Builder[T] {
+	return &Builder[T]{rules: rules}
+}
+
+// With returns a copy of b with opts appended. Later options win.
+func (b *Builder[T]) With(opts ...Option[T]) *Builder[T] {
+	nb := *b
+	nb.opts = append(append([]Option[T](nil), b.opts...), opts...)
+	return &nb
+}
+
+// Validate returns a copy of b with extra rules.
+func (b *Builder[T]) Validate(rules ...Rule[T]) *Builder[T] {
+	nb := *b
+	nb.rules = append(append([]Rule[T](nil), b.rules...), rules...)
+	return &nb
+}
+
+// Clone returns a copy of b that deep-copies every built value with fn.
+// Types holding slices or maps need this for Build to return a snapshot
+// that shares nothing with options' arguments.
+func (b *Builder[T]) Clone(fn func(T) T) *Builder[T] {
+	nb := *b
+	nb.clone = fn
+	return &nb
+}
+
+// Build applies every option to a zero T, runs every rule and returns the
+// value, or the zero T and all rule failures joined together.
+func (b *Builder[T]) Build() (T, error) {
+	var v T
+	for _, opt := range b.opts {
+		opt(&v)
+	}
+
+	var errs []error
+	for _, rule := range b.rules {
+		if err := rule(v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		var zero T
+		return zero, fmt.Errorf("invalid %T: %w", v, errors.Join(errs...))
+	}
+	if b.clone != nil {
+		v = b.clone(v)
+	}
+	return v, nil
+}
+
+// MustBuild is Build for values known to be valid, such as test fixtures.
+func (b *Builder[T]) MustBuild() T {
+	v, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// Required fails when get returns the zero value of V.
+func Required[T any, V comparable](field string, get func(T) V) Rule[T] {
+	return func(v T) error {
+		var zero V
+		if get(v) == zero {
+			return Errorf(field, "is required")
+		}
+		return nil
+	}
+}
+
+// Check fails with msg when ok returns false.
+func Check[T any](field, msg string, ok func(T) bool) Rule[T] {
+	return func(v T) error {
+		if !ok(v) {
+			return Errorf(field, "%s", msg)
+		}
+		return nil
+	}
+}
+
+// ErrUnknownPreset is returned by Presets.Get for unregistered names.
+var ErrUnknownPreset = errors.New("unknown preset")
+
+// Presets is a named catalogue of partially configured builders. Because
+// builders are immutable, a preset can be tweaked with With without
+// affecting anyone else who uses it.
+type Presets[T any] struct {
+	mu sync.RWMutex
+	m  map[string]*Builder[T]
+}
+
+// Register stores b under name, replacing any previous preset.
+func (p *Presets[T]) Register(name string, b *Builder[T]) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.m == nil {
+		p.m = make(map[string]*Builder[T])
+	}
+	p.m[name] = b
+}
+
+// Get returns the preset registered under name.
+func (p *Presets[T]) Get(name string) (*Builder[T], error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	b, ok := p.m[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownPreset, name)
+	}
+	return b, nil
+}
+
+// Names lists the registered presets in sorted order.
+func (p *Presets[T]) Names() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	names := make([]string, 0, len(p.m))
+	for name := range p.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
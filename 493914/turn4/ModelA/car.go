@@ -0,0 +1,56 @@
+package main
+
+import "turing/493914/turn4/ModelA/builder"
+
+// Car represents a car object
+type Car struct {
+	Make         string
+	Model        string
+	Year         int
+	Color        string
+	Engine       string
+	Transmission string
+	Trim         string
+	Sunroof      bool
+	GPS          bool
+	Leather      bool
+}
+
+type CarOption = builder.Option[Car]
+
+func CarMake(make string) CarOption      { return func(c *Car) { c.Make = make } }
+func CarModel(model string) CarOption    { return func(c *Car) { c.Model = model } }
+func CarYear(year int) CarOption         { return func(c *Car) { c.Year = year } }
+func CarColor(color string) CarOption    { return func(c *Car) { c.Color = color } }
+func CarEngine(engine string) CarOption  { return func(c *Car) { c.Engine = engine } }
+func CarTransmission(t string) CarOption { return func(c *Car) { c.Transmission = t } }
+func CarTrim(trim string) CarOption      { return func(c *Car) { c.Trim = trim } }
+func CarSunroof(sunroof bool) CarOption  { return func(c *Car) { c.Sunroof = sunroof } }
+func CarGPS(gps bool) CarOption          { return func(c *Car) { c.GPS = gps } }
+func CarLeather(leather bool) CarOption  { return func(c *Car) { c.Leather = leather } }
+
+// NewCarBuilder creates a builder that rejects incomplete or inconsistent cars.
+func NewCarBuilder() *builder.Builder[Car] {
+	return builder.New(
+		builder.Required("Make", func(c Car) string { return c.Make }),
+		builder.Required("Model", func(c Car) string { return c.Model }),
+		builder.Check("Year", "must be between 1886 and 2100", func(c Car) bool {
+			return c.Year >= 1886 && c.Year <= 2100
+		}),
+		builder.Check("GPS", "requires a trim level", func(c Car) bool {
+			return !c.GPS || c.Trim != ""
+		}),
+		builder.Check("Leather", "is not available on the Base trim", func(c Car) bool {
+			return !c.Leather || c.Trim != "Base"
+		}),
+	)
+}
+
+// carPresets holds the factory configurations customers usually start from.
+var carPresets builder.Presets[Car]
+
+func init() {
+	camry := NewCarBuilder().With(CarMake("Toyota"), CarModel("Camry"), CarYear(2023), CarEngine("2.5L I4"), CarTransmission("Automatic"))
+	carPresets.Register("camry-base", camry.With(CarTrim("Base")))
+	carPresets.Register("camry-xle", camry.With(CarTrim("XLE"), CarSunroof(true), CarGPS(true), CarLeather(true)))
+}
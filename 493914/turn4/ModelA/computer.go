@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+
+	"turing/493914/turn4/ModelA/builder"
+)
+
+// Computer represents a computer configuration
+type Computer struct {
+	CPU             string
+	GPU             string
+	Memory          int
+	Storage         string
+	OperatingSystem string
+	Monitor         string
+}
+
+type ComputerOption = builder.Option[Computer]
+
+func ComputerCPU(cpu string) ComputerOption         { return func(c *Computer) { c.CPU = cpu } }
+func ComputerGPU(gpu string) ComputerOption         { return func(c *Computer) { c.GPU = gpu } }
+func ComputerMemory(gb int) ComputerOption          { return func(c *Computer) { c.Memory = gb } }
+func ComputerStorage(storage string) ComputerOption { return func(c *Computer) { c.Storage = storage } }
+func ComputerOS(os string) ComputerOption           { return func(c *Computer) { c.OperatingSystem = os } }
+func ComputerMonitor(monitor string) ComputerOption { return func(c *Computer) { c.Monitor = monitor } }
+
+// NewComputerBuilder creates a builder for bootable computer configurations.
+func NewComputerBuilder() *builder.Builder[Computer] {
+	return builder.New(
+		builder.Required("CPU", func(c Computer) string { return c.CPU }),
+		builder.Required("Storage", func(c Computer) string { return c.Storage }),
+		builder.Check("Memory", "must be at least 4 GB", func(c Computer) bool { return c.Memory >= 4 }),
+		builder.Check("Memory", "Windows 11 needs at least 8 GB", func(c Computer) bool {
+			return c.OperatingSystem != "Windows 11" || c.Memory >= 8
+		}),
+		builder.Check("GPU", "a 4K monitor needs a discrete GPU", func(c Computer) bool {
+			return !strings.Contains(c.Monitor, "4K") || c.GPU != ""
+		}),
+	)
+}
@@ -0,0 +1,45 @@
+package main
+
+import "turing/493914/turn4/ModelA/builder"
+
+// Configuration represents the configuration settings
+type Configuration struct {
+	DatabaseUsername string
+	DatabasePassword string
+	DatabaseHost     string
+	DatabasePort     int
+	LogLevel         string
+	APIKey           string
+}
+
+type ConfigOption = builder.Option[Configuration]
+
+func ConfigDatabaseUsername(u string) ConfigOption {
+	return func(c *Configuration) { c.DatabaseUsername = u }
+}
+func ConfigDatabasePassword(p string) ConfigOption {
+	return func(c *Configuration) { c.DatabasePassword = p }
+}
+func ConfigDatabaseHost(h string) ConfigOption { return func(c *Configuration) { c.DatabaseHost = h } }
+func ConfigDatabasePort(p int) ConfigOption    { return func(c *Configuration) { c.DatabasePort = p } }
+func ConfigLogLevel(l string) ConfigOption     { return func(c *Configuration) { c.LogLevel = l } }
+func ConfigAPIKey(k string) ConfigOption       { return func(c *Configuration) { c.APIKey = k } }
+
+var logLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+
+// NewConfigurationBuilder creates a builder with defaults for the optional
+// settings; later options override them.
+func NewConfigurationBuilder() *builder.Builder[Configuration] {
+	return builder.New(
+		builder.Required("DatabaseHost", func(c Configuration) string { return c.DatabaseHost }),
+		builder.Check("DatabasePort", "must be between 1 and 65535", func(c Configuration) bool {
+			return c.DatabasePort > 0 && c.DatabasePort <= 65535
+		}),
+		builder.Check("DatabasePassword", "is required when a username is set", func(c Configuration) bool {
+			return c.DatabaseUsername == "" || c.DatabasePassword != ""
+		}),
+		builder.Check("LogLevel", "must be one of debug, info, warn, error", func(c Configuration) bool {
+			return logLevels[c.LogLevel]
+		}),
+	).With(ConfigDatabasePort(5432), ConfigLogLevel("info"))
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+
+	"turing/493914/turn4/ModelA/builder"
+)
+
+// Email is an outgoing message with optional delivery callbacks.
+type Email struct {
+	To        string
+	CC        []string
+	Subject   string
+	Body      string
+	OnSuccess func()
+	OnFailure func()
+}
+
+type EmailOption = builder.Option[Email]
+
+func EmailTo(to string) EmailOption           { return func(e *Email) { e.To = to } }
+func EmailSubject(subject string) EmailOption { return func(e *Email) { e.Subject = subject } }
+func EmailBody(body string) EmailOption       { return func(e *Email) { e.Body = body } }
+func EmailOnSuccess(fn func()) EmailOption    { return func(e *Email) { e.OnSuccess = fn } }
+func EmailOnFailure(fn func()) EmailOption    { return func(e *Email) { e.OnFailure = fn } }
+
+// EmailCC appends carbon-copy recipients.
+func EmailCC(addrs ...string) EmailOption {
+	return func(e *Email) { e.CC = append(e.CC, addrs...) }
+}
+
+// NewEmailBuilder creates a builder whose built emails never share their CC
+// slice with the builder or with each other.
+func NewEmailBuilder() *builder.Builder[Email] {
+	return builder.New(
+		builder.Check("To", "must be an email address", func(e Email) bool { return strings.Contains(e.To, "@") }),
+		builder.Check("CC", "must only contain email addresses", func(e Email) bool {
+			for _, addr := range e.CC {
+				if !strings.Contains(addr, "@") {
+					return false
+				}
+			}
+			return true
+		}),
+		builder.Check("Body", "subject or body is required", func(e Email) bool { return e.Subject != "" || e.Body != "" }),
+	).Clone(func(e Email) Email {
+		e.CC = append([]string(nil), e.CC...)
+		return e
+	})
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"turing/493914/turn4/ModelA/builder"
+)
+
+func main() {
+	// Creating a car object using a builder
+	car, err := NewCarBuilder().With(
+		CarMake("Toyota"),
+		CarModel("Camry"),
+		CarYear(2023),
+		CarColor("Blue"),
+		CarEngine("2.5L I4"),
+		CarTransmission("Automatic"),
+		CarTrim("XLE"),
+		CarSunroof(true),
+		CarGPS(true),
+		CarLeather(true),
+	).Build()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("Car: %+v\n", car)
+
+	// Cross-field validation: GPS without a trim level is rejected, and
+	// every failing rule is reported, not just the first.
+	_, err = NewCarBuilder().With(CarMake("Toyota"), CarYear(1800), CarGPS(true)).Build()
+	fmt.Println("Invalid car:", err)
+	var fieldErr *builder.FieldError
+	if errors.As(err, &fieldErr) {
+		fmt.Println("First problem is with field", fieldErr.Field)
+	}
+
+	// Presets are clonable starting points; tweaking one leaves it intact.
+	fmt.Println("Car presets:", carPresets.Names())
+	xle, _ := carPresets.Get("camry-xle")
+	red, err := xle.With(CarColor("Red")).Build()
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	plain := xle.MustBuild()
+	fmt.Printf("Red XLE: %s %s %s, preset color still %q\n", red.Color, red.Make, red.Trim, plain.Color)
+	if _, err := carPresets.Get("corolla"); errors.Is(err, builder.ErrUnknownPreset) {
+		fmt.Println("Error:", err)
+	}
+
+	// Built values are snapshots: changing one doesn't touch the builder.
+	emails := NewEmailBuilder().With(EmailTo("correct@example.com"), EmailSubject("Welcome"), EmailCC("ops@example.com"))
+	first := emails.MustBuild()
+	first.CC[0] = "changed@example.com"
+	second := emails.MustBuild()
+	fmt.Printf("Email CC after mutating first build: %v\n", second.CC)
+
+	computer, err := NewComputerBuilder().With(
+		ComputerCPU("Ryzen 7"),
+		ComputerMemory(16),
+		ComputerStorage("1TB NVMe"),
+		ComputerOS("Windows 11"),
+		ComputerMonitor("27\" 4K"),
+	).Build()
+	fmt.Printf("Computer: %+v, error: %v\n", computer, err)
+
+	user, err := NewUserBuilder().With(UserID(1), UserName("Alice")).Build()
+	fmt.Printf("User: %+v, error: %v\n", user, err)
+
+	config, err := NewConfigurationBuilder().With(
+		ConfigDatabaseHost("localhost"),
+		ConfigDatabaseUsername("admin"),
+		ConfigDatabasePassword("secret"),
+		ConfigAPIKey("key"),
+	).Build()
+	fmt.Printf("Config: %+v, error: %v\n", config, err)
+}
@@ -0,0 +1,22 @@
+package main
+
+import "turing/493914/turn4/ModelA/builder"
+
+// User is an application user.
+type User struct {
+	ID   int
+	Name string
+}
+
+type UserOption = builder.Option[User]
+
+func UserID(id int) UserOption        { return func(u *User) { u.ID = id } }
+func UserName(name string) UserOption { return func(u *User) { u.Name = name } }
+
+// NewUserBuilder creates a builder that requires a positive ID and a name.
+func NewUserBuilder() *builder.Builder[User] {
+	return builder.New(
+		builder.Check("ID", "must be positive", func(u User) bool { return u.ID > 0 }),
+		builder.Required("Name", func(u User) string { return u.Name }),
+	)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	mrand "math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	traceIDHeader   = "X-Trace-ID"
+	traceparent     = "traceparent"
+
+	numTasks           = 5
+	defaultTaskTimeout = 2 * time.Second
+	maxTaskWork        = 3 * time.Second
+)
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	traceIDKey
+)
+
+// LogEntry is one JSON log line. RequestID and TraceID come from the
+// request context so every line of one request can be correlated.
+type LogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Message   string    `json:"message"`
+	RequestID string    `json:"requestID,omitempty"`
+	TraceID   string    `json:"traceID,omitempty"`
+	TaskID    *int      `json:"taskID,omitempty"`
+}
+
+func logStructured(ctx context.Context, level string, message string, taskID *int) {
+	entry := LogEntry{
+		Timestamp: time.Now(),
+		Level:     level,
+		Message:   message,
+		TaskID:    taskID,
+	}
+	entry.RequestID, _ = ctx.Value(requestIDKey).(string)
+	entry.TraceID, _ = ctx.Value(traceIDKey).(string)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Error marshalling log entry: %v\n", err)
+		return
+	}
+	log.Println(string(data))
+}
+
+// withCorrelation reads the request and trace IDs from the incoming
+// headers, generating them when absent, stores them in the request context
+// and echoes them back on the response.
+func withCorrelation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = newID(8)
+		}
+		traceID := r.Header.Get(traceIDHeader)
+		if traceID == "" {
+			traceID = traceIDFromTraceparent(r.Header.Get(traceparent))
+		}
+		if traceID == "" {
+			traceID = newID(16)
+		}
+
+		w.Header().Set(requestIDHeader, requestID)
+		w.Header().Set(traceIDHeader, traceID)
+
+		ctx := context.WithValue(r.Context(), requestIDKey, requestID)
+		ctx = context.WithValue(ctx, traceIDKey, traceID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// traceIDFromTraceparent extracts the trace-id field of a W3C traceparent
+// header ("00-<32 hex>-<16 hex>-<2 hex>").
+func traceIDFromTraceparent(h string) string {
+	parts := strings.Split(h, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return ""
+	}
+	return parts[1]
+}
+
+func newID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// TaskResult is the outcome of one task as reported to the client.
+type TaskResult struct {
+	ID         int    `json:"id"`
+	Status     string `json:"status"`
+	DurationMS int64  `json:"durationMs"`
+	Error      string `json:"error,omitempty"`
+}
+
+type handlerResponse struct {
+	RequestID string       `json:"requestId"`
+	TraceID   string       `json:"traceId"`
+	Tasks     []TaskResult `json:"tasks"`
+}
+
+// runTask simulates work that takes d. The work itself selects on ctx, so a
+// per-task deadline or a client disconnect stops it immediately rather than
+// after the work would have finished.
+// errTaskTimeout is the cause of a task context that hit taskTimeout, telling
+// it apart from the request context ending.
+var errTaskTimeout = errors.New("task timeout")
+
+func runTask(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	taskTimeout := defaultTaskTimeout
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid timeout", http.StatusBadRequest)
+			return
+		}
+		taskTimeout = d
+	}
+
+	// Each goroutine writes only its own slot, so no mutex is needed and
+	// results come back in task order.
+	results := make([]TaskResult, numTasks)
+	var wg sync.WaitGroup
+	wg.Add(numTasks)
+
+	for i := 0; i < numTasks; i++ {
+		go func(taskID int) {
+			defer wg.Done()
+
+			taskCtx, cancel := context.WithTimeoutCause(ctx, taskTimeout, errTaskTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := runTask(taskCtx, time.Duration(mrand.Int63n(int64(maxTaskWork))))
+			res := TaskResult{ID: taskID, Status: "completed", DurationMS: time.Since(start).Milliseconds()}
+
+			switch {
+			case err == nil:
+				logStructured(ctx, "INFO", fmt.Sprintf("Task %d completed", taskID), &taskID)
+			case errors.Is(context.Cause(taskCtx), errTaskTimeout):
+				// The task's own deadline fired first, even if the request
+				// has ended since.
+				res.Status, res.Error = "timeout", fmt.Sprintf("exceeded %s", taskTimeout)
+				logStructured(ctx, "WARN", fmt.Sprintf("Task %d timed out", taskID), &taskID)
+			case ctx.Err() != nil:
+				// The request context ended first: the client went away.
+				res.Status, res.Error = "canceled", ctx.Err().Error()
+				logStructured(ctx, "WARN", fmt.Sprintf("Task %d canceled: client disconnected", taskID), &taskID)
+			default:
+				res.Status, res.Error = "failed", err.Error()
+				logStructured(ctx, "ERROR", fmt.Sprintf("Task %d failed: %v", taskID, err), &taskID)
+			}
+			results[taskID] = res
+		}(i)
+	}
+
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		logStructured(ctx, "WARN", "Client disconnected, dropping response", nil)
+		return
+	}
+
+	resp := handlerResponse{
+		RequestID: w.Header().Get(requestIDHeader),
+		TraceID:   w.Header().Get(traceIDHeader),
+		Tasks:     results,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logStructured(ctx, "ERROR", fmt.Sprintf("Error writing response: %v", err), nil)
+		return
+	}
+	logStructured(ctx, "INFO", "All tasks finished", nil)
+}
+
+func main() {
+	http.Handle("/", withCorrelation(http.HandlerFunc(handler)))
+	log.Println("Server starting on :8080")
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		log.Fatalf("Error starting server: %v\n", err)
+	}
+}
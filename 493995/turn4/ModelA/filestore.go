@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// FileStore is a UserStore backed by an append-only JSON-lines log. Every
+// change is written and synced before it becomes visible; opening the file
+// replays the log to rebuild the in-memory state.
+type FileStore struct {
+	*store
+	f    *os.File
+	size int64 // offset just past the last complete record
+	// failed is set when a failed append could not be cut back off the
+	// log; every later append returns it rather than writing after the
+	// partial record.
+	failed error
+}
+
+// OpenFileStore opens or creates the log at path and replays it.
+func OpenFileStore(path string) (*FileStore, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	st, size, err := replay(f, path)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	fs := &FileStore{f: f, size: size}
+	fs.store = &store{st: st, persist: fs.append}
+	return fs, nil
+}
+
+// replay applies every record in f to a new state. A final record without
+// its trailing newline is what a crash in the middle of append leaves
+// behind; it was never acknowledged, so it is cut off rather than making
+// the log unopenable. Any other bad record is an error.
+func replay(f *os.File, path string) (*state, int64, error) {
+	st := newState()
+	r := bufio.NewReader(f)
+	var good int64 // offset just past the last complete record
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if err == io.EOF {
+			if len(data) > 0 {
+				log.Printf("%s:%d: discarding truncated final record (%d bytes)", path, line, len(data))
+				if err := f.Truncate(good); err != nil {
+					return nil, 0, err
+				}
+			}
+			return st, good, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		var ev event
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return nil, 0, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if err := st.apply(ev); err != nil {
+			return nil, 0, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		good += int64(len(data))
+	}
+}
+
+// append writes one record. It is called with the store's write lock
+// held. A failed or short write is truncated away so the next record does
+// not start in the middle of this one.
+func (fs *FileStore) append(ev event) error {
+	if fs.failed != nil {
+		return fs.failed
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = fs.f.Write(data)
+	if err == nil {
+		err = fs.f.Sync()
+	}
+	if err != nil {
+		if terr := fs.f.Truncate(fs.size); terr != nil {
+			fs.failed = fmt.Errorf("data file left with a partial record (%v): %w", err, terr)
+		}
+		return err
+	}
+	fs.size += int64(len(data))
+	return nil
+}
+
+// Close closes the underlying log file.
+func (fs *FileStore) Close() error {
+	return fs.f.Close()
+}
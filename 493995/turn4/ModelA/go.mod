@@ -0,0 +1,5 @@
+module ModelA
+
+go 1.22.1
+
+require github.com/gorilla/mux v1.8.1
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// server wires the HTTP routes to a UserStore.
+type server struct {
+	store UserStore
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrUserNotFound):
+		http.Error(w, "User not found", http.StatusNotFound)
+	case errors.Is(err, ErrPostNotFound):
+		http.Error(w, "Post not found", http.StatusNotFound)
+	default:
+		log.Printf("store error: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil || id <= 0 {
+		http.Error(w, "Error parsing "+name, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeUser reads a user body. IDs come from the URL or the server, so a
+// body that tries to set one is rejected.
+func decodeUser(w http.ResponseWriter, r *http.Request) (User, bool) {
+	var user User
+	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
+		http.Error(w, "Error decoding JSON request body", http.StatusBadRequest)
+		return User{}, false
+	}
+	if user.ID != 0 {
+		http.Error(w, "User ID is assigned by the server", http.StatusBadRequest)
+		return User{}, false
+	}
+	if strings.TrimSpace(user.Name) == "" {
+		http.Error(w, "User name is required", http.StatusBadRequest)
+		return User{}, false
+	}
+	return user, true
+}
+
+func decodePost(w http.ResponseWriter, r *http.Request) (Post, bool) {
+	var post Post
+	if err := json.NewDecoder(r.Body).Decode(&post); err != nil {
+		http.Error(w, "Error decoding JSON request body", http.StatusBadRequest)
+		return Post{}, false
+	}
+	if post.ID != 0 || post.UserID != 0 {
+		http.Error(w, "Post IDs are assigned by the server", http.StatusBadRequest)
+		return Post{}, false
+	}
+	if strings.TrimSpace(post.Title) == "" {
+		http.Error(w, "Post title is required", http.StatusBadRequest)
+		return Post{}, false
+	}
+	return post, true
+}
+
+// Create a new user
+func (s *server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := decodeUser(w, r)
+	if !ok {
+		return
+	}
+	user, err := s.store.CreateUser(user)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/users/"+strconv.Itoa(user.ID))
+	writeJSON(w, http.StatusCreated, user)
+}
+
+// Get an existing user
+func (s *server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	user, err := s.store.GetUser(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// Update an existing user
+func (s *server) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	user, ok := decodeUser(w, r)
+	if !ok {
+		return
+	}
+	user.ID = id
+	user, err := s.store.UpdateUser(user)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// Delete an existing user and all of their posts
+func (s *server) handleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	if err := s.store.DeleteUser(id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Get total number of users
+func (s *server) handleGetUserCount(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]int{"count": s.store.CountUsers()})
+}
+
+func (s *server) handleAddPost(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	post, ok := decodePost(w, r)
+	if !ok {
+		return
+	}
+	post, err := s.store.AddPost(userID, post)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/users/"+strconv.Itoa(userID)+"/posts/"+strconv.Itoa(post.ID))
+	writeJSON(w, http.StatusCreated, post)
+}
+
+func (s *server) handleListPosts(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	posts, err := s.store.ListPosts(userID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, posts)
+}
+
+func (s *server) handleGetPost(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	postID, ok := pathID(w, r, "postID")
+	if !ok {
+		return
+	}
+	post, err := s.store.GetPost(userID, postID)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, post)
+}
+
+func (s *server) handleUpdatePost(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	postID, ok := pathID(w, r, "postID")
+	if !ok {
+		return
+	}
+	post, ok := decodePost(w, r)
+	if !ok {
+		return
+	}
+	post.ID = postID
+	post, err := s.store.UpdatePost(userID, post)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, post)
+}
+
+func (s *server) routes() *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/users", s.handleCreateUser).Methods("POST")
+	router.HandleFunc("/users/{id}", s.handleGetUser).Methods("GET")
+	router.HandleFunc("/users/{id}", s.handleUpdateUser).Methods("PUT")
+	router.HandleFunc("/users/{id}", s.handleDeleteUser).Methods("DELETE")
+	router.HandleFunc("/users/{id}/posts", s.handleAddPost).Methods("POST")
+	router.HandleFunc("/users/{id}/posts", s.handleListPosts).Methods("GET")
+	router.HandleFunc("/users/{id}/posts/{postID}", s.handleGetPost).Methods("GET")
+	router.HandleFunc("/users/{id}/posts/{postID}", s.handleUpdatePost).Methods("PUT")
+	router.HandleFunc("/user-count", s.handleGetUserCount).Methods("GET")
+	return router
+}
+
+func main() {
+	if err := run(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run is main without log.Fatal, so the deferred Close runs on every exit
+// path, including a graceful stop on SIGINT or SIGTERM.
+func run() error {
+	dataFile := flag.String("data", "", "append-only data file; empty keeps everything in memory")
+	addr := flag.String("addr", ":8080", "listen address")
+	flag.Parse()
+
+	var store UserStore = NewMemoryStore()
+	if *dataFile != "" {
+		fs, err := OpenFileStore(*dataFile)
+		if err != nil {
+			return fmt.Errorf("opening data file: %w", err)
+		}
+		defer fs.Close()
+		store = fs
+	}
+
+	s := &server{store: store}
+	srv := &http.Server{Addr: *addr, Handler: s.routes()}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	log.Println("Server running on", *addr)
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
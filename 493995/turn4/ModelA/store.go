@@ -0,0 +1,242 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type Post struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"userId"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrPostNotFound = errors.New("post not found")
+)
+
+// UserStore persists users and their posts. Implementations assign IDs,
+// keep posts attached to an existing user, and delete a user's posts
+// together with the user.
+type UserStore interface {
+	CreateUser(u User) (User, error)
+	GetUser(id int) (User, error)
+	UpdateUser(u User) (User, error)
+	DeleteUser(id int) error
+	CountUsers() int
+
+	AddPost(userID int, p Post) (Post, error)
+	GetPost(userID, postID int) (Post, error)
+	UpdatePost(userID int, p Post) (Post, error)
+	ListPosts(userID int) ([]Post, error)
+}
+
+// event is a single state change. The memory store applies events
+// directly; the file store appends them to its log first.
+type event struct {
+	Op   string `json:"op"`
+	User *User  `json:"user,omitempty"`
+	Post *Post  `json:"post,omitempty"`
+	ID   int    `json:"id,omitempty"`
+}
+
+const (
+	opPutUser    = "user.put"
+	opDeleteUser = "user.delete"
+	opPutPost    = "post.put"
+)
+
+// state is the in-memory data shared by every UserStore implementation.
+// It does no locking of its own.
+type state struct {
+	users       map[int]User
+	posts       map[int]Post
+	postsByUser map[int]map[int]struct{}
+	lastUserID  int
+	lastPostID  int
+}
+
+func newState() *state {
+	return &state{
+		users:       make(map[int]User),
+		posts:       make(map[int]Post),
+		postsByUser: make(map[int]map[int]struct{}),
+	}
+}
+
+// apply changes the state by one event. Events come from a log file that
+// may have been edited or damaged, so a malformed one is an error rather
+// than a panic.
+func (s *state) apply(ev event) error {
+	switch ev.Op {
+	case opPutUser:
+		if ev.User == nil {
+			return fmt.Errorf("%s event without a user", ev.Op)
+		}
+		u := *ev.User
+		s.users[u.ID] = u
+		if u.ID > s.lastUserID {
+			s.lastUserID = u.ID
+		}
+	case opDeleteUser:
+		for postID := range s.postsByUser[ev.ID] {
+			delete(s.posts, postID)
+		}
+		delete(s.postsByUser, ev.ID)
+		delete(s.users, ev.ID)
+	case opPutPost:
+		if ev.Post == nil {
+			return fmt.Errorf("%s event without a post", ev.Op)
+		}
+		p := *ev.Post
+		s.posts[p.ID] = p
+		if s.postsByUser[p.UserID] == nil {
+			s.postsByUser[p.UserID] = make(map[int]struct{})
+		}
+		s.postsByUser[p.UserID][p.ID] = struct{}{}
+		if p.ID > s.lastPostID {
+			s.lastPostID = p.ID
+		}
+	default:
+		return fmt.Errorf("unknown event op %q", ev.Op)
+	}
+	return nil
+}
+
+// store implements UserStore on top of state. persist, if set, is called
+// with every event before it is applied; an error leaves state untouched.
+type store struct {
+	mu      sync.RWMutex
+	st      *state
+	persist func(event) error
+}
+
+// NewMemoryStore returns a UserStore that keeps everything in memory.
+func NewMemoryStore() UserStore {
+	return &store{st: newState()}
+}
+
+func (s *store) commit(ev event) error {
+	if s.persist != nil {
+		if err := s.persist(ev); err != nil {
+			return err
+		}
+	}
+	return s.st.apply(ev)
+}
+
+func (s *store) CreateUser(u User) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u.ID = s.st.lastUserID + 1
+	if err := s.commit(event{Op: opPutUser, User: &u}); err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
+
+func (s *store) GetUser(id int) (User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	u, ok := s.st.users[id]
+	if !ok {
+		return User{}, ErrUserNotFound
+	}
+	return u, nil
+}
+
+func (s *store) UpdateUser(u User) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.st.users[u.ID]; !ok {
+		return User{}, ErrUserNotFound
+	}
+	if err := s.commit(event{Op: opPutUser, User: &u}); err != nil {
+		return User{}, err
+	}
+	return u, nil
+}
+
+func (s *store) DeleteUser(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.st.users[id]; !ok {
+		return ErrUserNotFound
+	}
+	return s.commit(event{Op: opDeleteUser, ID: id})
+}
+
+func (s *store) CountUsers() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.st.users)
+}
+
+func (s *store) AddPost(userID int, p Post) (Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.st.users[userID]; !ok {
+		return Post{}, ErrUserNotFound
+	}
+	p.ID = s.st.lastPostID + 1
+	p.UserID = userID
+	if err := s.commit(event{Op: opPutPost, Post: &p}); err != nil {
+		return Post{}, err
+	}
+	return p, nil
+}
+
+func (s *store) GetPost(userID, postID int) (Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lookupPost(userID, postID)
+}
+
+func (s *store) UpdatePost(userID int, p Post) (Post, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.lookupPost(userID, p.ID); err != nil {
+		return Post{}, err
+	}
+	p.UserID = userID
+	if err := s.commit(event{Op: opPutPost, Post: &p}); err != nil {
+		return Post{}, err
+	}
+	return p, nil
+}
+
+func (s *store) ListPosts(userID int) ([]Post, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.st.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+	posts := make([]Post, 0, len(s.st.postsByUser[userID]))
+	for postID := range s.st.postsByUser[userID] {
+		posts = append(posts, s.st.posts[postID])
+	}
+	sort.Slice(posts, func(i, j int) bool { return posts[i].ID < posts[j].ID })
+	return posts, nil
+}
+
+// lookupPost finds a post that belongs to userID. The caller holds s.mu.
+func (s *store) lookupPost(userID, postID int) (Post, error) {
+	if _, ok := s.st.users[userID]; !ok {
+		return Post{}, ErrUserNotFound
+	}
+	p, ok := s.st.posts[postID]
+	if !ok || p.UserID != userID {
+		return Post{}, ErrPostNotFound
+	}
+	return p, nil
+}
@@ -0,0 +1,445 @@
+package main
+
+import (
+	"container/heap"
+	"errors"
+	"sync"
+	"time"
+)
+
+type Notification struct {
+	ID        uint64
+	UserID    int
+	Type      string
+	Data      interface{}
+	Priority  int // Higher numbers indicate higher priority
+	CreatedAt time.Time
+}
+
+// Delivery is what a subscriber receives. Attempt starts at 1 and grows
+// each time the notification is redelivered for lack of an Ack.
+type Delivery struct {
+	Notification
+	Attempt int
+}
+
+// Policy decides what happens when a subscriber's buffer is full. Dropped
+// deliveries are not lost: they stay unacknowledged and are redelivered, or
+// moved to the inbox, once AckTimeout passes.
+type Policy int
+
+const (
+	// DropNewest discards the delivery that does not fit.
+	DropNewest Policy = iota
+	// DropOldest evicts the oldest buffered delivery to make room.
+	DropOldest
+	// Block waits up to HubOptions.BlockTimeout for room.
+	Block
+)
+
+type HubOptions struct {
+	AckTimeout   time.Duration
+	MaxAttempts  int
+	BlockTimeout time.Duration
+	Inbox        Inbox
+}
+
+type HubStats struct {
+	Sent, Delivered, Dropped, Redelivered, Stored, Acked uint64
+}
+
+var (
+	ErrHubClosed      = errors.New("notification hub is closed")
+	ErrNegativeBuffer = errors.New("subscription buffer must not be negative")
+)
+
+// Subscription is one of a user's connections.
+type Subscription struct {
+	UserID int
+	C      <-chan Delivery
+
+	hub    *Hub
+	ch     chan Delivery
+	policy Policy
+	mu     sync.Mutex
+	closed bool
+}
+
+// Ack confirms that the subscriber has handled notification id.
+func (s *Subscription) Ack(id uint64) {
+	s.hub.ack(s.UserID, id)
+}
+
+// Close unsubscribes. If this was the user's last subscription, its
+// unacknowledged deliveries go straight to the inbox; otherwise the user's
+// other subscriptions, which received the same deliveries, can still ack
+// them before AckTimeout.
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
+
+type pendingKey struct {
+	userID int
+	id     uint64
+}
+
+type pending struct {
+	n        Notification
+	attempt  int
+	deadline time.Time
+}
+
+// Hub routes notifications to subscribers in priority order.
+type Hub struct {
+	opts HubOptions
+
+	mu      sync.Mutex
+	queue   priorityQueue
+	seq     uint64
+	nextID  uint64
+	subs    map[int][]*Subscription
+	groups  map[string]map[int]struct{}
+	pending map[pendingKey]*pending
+	stats   HubStats
+	closed  bool
+
+	wake chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewHub starts a hub's dispatcher and redelivery loops.
+func NewHub(opts HubOptions) *Hub {
+	if opts.AckTimeout <= 0 {
+		opts.AckTimeout = 5 * time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.BlockTimeout <= 0 {
+		opts.BlockTimeout = time.Second
+	}
+	if opts.Inbox == nil {
+		opts.Inbox = NewMemoryInbox()
+	}
+	h := &Hub{
+		opts:    opts,
+		subs:    make(map[int][]*Subscription),
+		groups:  make(map[string]map[int]struct{}),
+		pending: make(map[pendingKey]*pending),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	h.wg.Add(2)
+	go h.dispatch()
+	go h.redeliver()
+	return h
+}
+
+// Close stops the hub and closes every subscription channel. Notifications
+// still queued or awaiting an ack are moved to the inbox.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return
+	}
+	h.closed = true
+	close(h.done)
+	h.mu.Unlock()
+	h.wg.Wait()
+
+	h.mu.Lock()
+	var all []*Subscription
+	for _, subs := range h.subs {
+		all = append(all, subs...)
+	}
+	h.subs = make(map[int][]*Subscription)
+	var leftover []Notification
+	for h.queue.Len() > 0 {
+		leftover = append(leftover, heap.Pop(&h.queue).(*queued).n)
+	}
+	for key, p := range h.pending {
+		delete(h.pending, key)
+		leftover = append(leftover, p.n)
+	}
+	h.stats.Stored += uint64(len(leftover))
+	h.mu.Unlock()
+	for _, s := range all {
+		s.closeChannel()
+	}
+	for _, n := range leftover {
+		h.opts.Inbox.Store(n)
+	}
+}
+
+// Subscribe opens a connection for userID with room for buffer undelivered
+// notifications. Anything waiting in the user's inbox is queued right away.
+func (h *Hub) Subscribe(userID, buffer int, policy Policy) (*Subscription, error) {
+	if buffer < 0 {
+		return nil, ErrNegativeBuffer
+	}
+	ch := make(chan Delivery, buffer)
+	s := &Subscription{UserID: userID, C: ch, hub: h, ch: ch, policy: policy}
+
+	// Drain outside the lock: the inbox may be real storage, and its I/O
+	// shouldn't stall every other hub operation.
+	stored := h.opts.Inbox.Drain(userID)
+
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		for _, n := range stored {
+			h.opts.Inbox.Store(n)
+		}
+		return nil, ErrHubClosed
+	}
+	h.subs[userID] = append(h.subs[userID], s)
+	for _, n := range stored {
+		h.enqueueLocked(n, 1)
+	}
+	h.mu.Unlock()
+	return s, nil
+}
+
+func (h *Hub) unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	subs := h.subs[s.UserID]
+	for i, sub := range subs {
+		if sub == s {
+			h.subs[s.UserID] = append(subs[:i:i], subs[i+1:]...)
+			break
+		}
+	}
+	var orphaned []Notification
+	if len(h.subs[s.UserID]) == 0 {
+		delete(h.subs, s.UserID)
+		for key, p := range h.pending {
+			if key.userID == s.UserID {
+				delete(h.pending, key)
+				orphaned = append(orphaned, p.n)
+			}
+		}
+		h.stats.Stored += uint64(len(orphaned))
+	}
+	h.mu.Unlock()
+	s.closeChannel()
+	for _, n := range orphaned {
+		h.opts.Inbox.Store(n)
+	}
+}
+
+func (s *Subscription) closeChannel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+}
+
+func (h *Hub) AddToGroup(groupID string, userID int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.groups[groupID] == nil {
+		h.groups[groupID] = make(map[int]struct{})
+	}
+	h.groups[groupID][userID] = struct{}{}
+}
+
+func (h *Hub) RemoveFromGroup(groupID string, userID int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.groups[groupID], userID)
+}
+
+// Send queues n for n.UserID and returns its assigned ID.
+func (h *Hub) Send(n Notification) (uint64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return 0, ErrHubClosed
+	}
+	h.nextID++
+	n.ID = h.nextID
+	n.CreatedAt = time.Now()
+	h.stats.Sent++
+	h.enqueueLocked(n, 1)
+	return n.ID, nil
+}
+
+// SendGroup queues a copy of n for every member of groupID. Each copy has
+// its own ID so members acknowledge independently.
+func (h *Hub) SendGroup(groupID string, n Notification) ([]uint64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, ErrHubClosed
+	}
+	var ids []uint64
+	for userID := range h.groups[groupID] {
+		h.nextID++
+		m := n
+		m.ID, m.UserID, m.CreatedAt = h.nextID, userID, time.Now()
+		h.stats.Sent++
+		h.enqueueLocked(m, 1)
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
+}
+
+func (h *Hub) Stats() HubStats {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.stats
+}
+
+func (h *Hub) enqueueLocked(n Notification, attempt int) {
+	h.seq++
+	heap.Push(&h.queue, &queued{n: n, attempt: attempt, seq: h.seq})
+	select {
+	case h.wake <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch pops the highest-priority notification and hands it to the
+// user's subscriptions, or to the inbox if the user is offline.
+func (h *Hub) dispatch() {
+	defer h.wg.Done()
+	for {
+		h.mu.Lock()
+		// Stop as soon as the hub closes, even with a backlog: Close
+		// moves whatever is still queued to the inbox.
+		select {
+		case <-h.done:
+			h.mu.Unlock()
+			return
+		default:
+		}
+		if h.queue.Len() == 0 {
+			h.mu.Unlock()
+			select {
+			case <-h.wake:
+				continue
+			case <-h.done:
+				return
+			}
+		}
+		q := heap.Pop(&h.queue).(*queued)
+		subs := append([]*Subscription(nil), h.subs[q.n.UserID]...)
+		if len(subs) == 0 {
+			h.stats.Stored++
+			h.mu.Unlock()
+			h.opts.Inbox.Store(q.n)
+			continue
+		}
+		h.pending[pendingKey{q.n.UserID, q.n.ID}] = &pending{
+			n:        q.n,
+			attempt:  q.attempt,
+			deadline: time.Now().Add(h.opts.AckTimeout),
+		}
+		h.mu.Unlock()
+
+		d := Delivery{Notification: q.n, Attempt: q.attempt}
+		for _, s := range subs {
+			delivered := h.deliver(s, d)
+			h.mu.Lock()
+			if delivered {
+				h.stats.Delivered++
+			} else {
+				h.stats.Dropped++
+			}
+			h.mu.Unlock()
+		}
+	}
+}
+
+// deliver applies the subscription's buffer policy. It never blocks longer
+// than BlockTimeout, so one slow subscriber cannot stall the hub forever.
+func (h *Hub) deliver(s *Subscription, d Delivery) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+
+	select {
+	case s.ch <- d:
+		return true
+	default:
+	}
+
+	switch s.policy {
+	case DropOldest:
+		select {
+		case <-s.ch:
+			h.mu.Lock()
+			h.stats.Dropped++
+			h.mu.Unlock()
+		default:
+		}
+		select {
+		case s.ch <- d:
+			return true
+		default:
+			return false
+		}
+	case Block:
+		timer := time.NewTimer(h.opts.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case s.ch <- d:
+			return true
+		case <-timer.C:
+			return false
+		case <-h.done:
+			return false
+		}
+	default:
+		return false
+	}
+}
+
+func (h *Hub) ack(userID int, id uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := pendingKey{userID, id}
+	if _, ok := h.pending[key]; ok {
+		delete(h.pending, key)
+		h.stats.Acked++
+	}
+}
+
+// redeliver requeues notifications whose ack deadline has passed. After
+// MaxAttempts they go to the inbox for the next time the user connects.
+func (h *Hub) redeliver() {
+	defer h.wg.Done()
+	ticker := time.NewTicker(h.opts.AckTimeout / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-h.done:
+			return
+		case now := <-ticker.C:
+			var expired []*pending
+			h.mu.Lock()
+			for key, p := range h.pending {
+				if now.After(p.deadline) {
+					delete(h.pending, key)
+					if p.attempt < h.opts.MaxAttempts {
+						h.stats.Redelivered++
+						h.enqueueLocked(p.n, p.attempt+1)
+					} else {
+						h.stats.Stored++
+						expired = append(expired, p)
+					}
+				}
+			}
+			h.mu.Unlock()
+			for _, p := range expired {
+				h.opts.Inbox.Store(p.n)
+			}
+		}
+	}
+}
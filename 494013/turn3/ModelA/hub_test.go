@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newTestHub(t *testing.T, opts HubOptions) *Hub {
+	t.Helper()
+	h := NewHub(opts)
+	t.Cleanup(h.Close)
+	return h
+}
+
+func recv(t *testing.T, s *Subscription) Delivery {
+	t.Helper()
+	select {
+	case d, ok := <-s.C:
+		if !ok {
+			t.Fatal("subscription closed")
+		}
+		return d
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a delivery")
+	}
+	return Delivery{}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSubscribeRejectsNegativeBuffer(t *testing.T) {
+	h := newTestHub(t, HubOptions{})
+	if _, err := h.Subscribe(1, -1, DropNewest); !errors.Is(err, ErrNegativeBuffer) {
+		t.Fatalf("err = %v, want ErrNegativeBuffer", err)
+	}
+}
+
+func TestDropNewestKeepsBufferedDelivery(t *testing.T) {
+	h := newTestHub(t, HubOptions{AckTimeout: time.Minute})
+	sub, _ := h.Subscribe(1, 1, DropNewest)
+	first, _ := h.Send(Notification{UserID: 1})
+	h.Send(Notification{UserID: 1})
+	waitFor(t, "second delivery to be dropped", func() bool { return h.Stats().Dropped == 1 })
+
+	if d := recv(t, sub); d.ID != first {
+		t.Fatalf("got notification %d, want %d", d.ID, first)
+	}
+}
+
+func TestDropOldestEvictsBufferedDelivery(t *testing.T) {
+	h := newTestHub(t, HubOptions{AckTimeout: time.Minute})
+	sub, _ := h.Subscribe(1, 1, DropOldest)
+	h.Send(Notification{UserID: 1})
+	second, _ := h.Send(Notification{UserID: 1})
+	waitFor(t, "both deliveries", func() bool { return h.Stats().Delivered == 2 })
+
+	if d := recv(t, sub); d.ID != second {
+		t.Fatalf("got notification %d, want %d", d.ID, second)
+	}
+	if got := h.Stats().Dropped; got != 1 {
+		t.Fatalf("Dropped = %d, want 1", got)
+	}
+}
+
+func TestBlockWaitsForRoom(t *testing.T) {
+	h := newTestHub(t, HubOptions{AckTimeout: time.Minute, BlockTimeout: time.Minute})
+	sub, _ := h.Subscribe(1, 0, Block)
+	id, _ := h.Send(Notification{UserID: 1})
+
+	time.Sleep(20 * time.Millisecond)
+	if d := recv(t, sub); d.ID != id {
+		t.Fatalf("got notification %d, want %d", d.ID, id)
+	}
+	waitFor(t, "delivery to be counted", func() bool { return h.Stats().Delivered == 1 })
+	if got := h.Stats().Dropped; got != 0 {
+		t.Fatalf("Dropped = %d, want 0", got)
+	}
+}
+
+func TestDeliveryOrderFollowsPriority(t *testing.T) {
+	h := newTestHub(t, HubOptions{AckTimeout: time.Minute})
+	h.Send(Notification{UserID: 1, Priority: 1})
+	h.Send(Notification{UserID: 1, Priority: 5})
+	h.Send(Notification{UserID: 1, Priority: 1})
+	waitFor(t, "notifications to reach the inbox", func() bool { return h.Stats().Stored == 3 })
+
+	sub, _ := h.Subscribe(1, 3, DropNewest)
+	want := []uint64{2, 1, 3}
+	for _, id := range want {
+		if d := recv(t, sub); d.ID != id {
+			t.Fatalf("got notification %d, want %d", d.ID, id)
+		}
+	}
+}
+
+func TestUnackedDeliveryIsRedeliveredThenStored(t *testing.T) {
+	inbox := NewMemoryInbox()
+	h := newTestHub(t, HubOptions{AckTimeout: 40 * time.Millisecond, MaxAttempts: 2, Inbox: inbox})
+	sub, _ := h.Subscribe(1, 4, DropNewest)
+	id, _ := h.Send(Notification{UserID: 1})
+
+	if d := recv(t, sub); d.ID != id || d.Attempt != 1 {
+		t.Fatalf("first delivery = %+v", d)
+	}
+	if d := recv(t, sub); d.ID != id || d.Attempt != 2 {
+		t.Fatalf("second delivery = %+v", d)
+	}
+	waitFor(t, "notification to reach the inbox", func() bool { return h.Stats().Stored == 1 })
+
+	stored := inbox.Drain(1)
+	if len(stored) != 1 || stored[0].ID != id {
+		t.Fatalf("inbox = %+v, want notification %d", stored, id)
+	}
+	if got := h.Stats().Redelivered; got != 1 {
+		t.Fatalf("Redelivered = %d, want 1", got)
+	}
+}
+
+func TestAckStopsRedelivery(t *testing.T) {
+	h := newTestHub(t, HubOptions{AckTimeout: 20 * time.Millisecond})
+	sub, _ := h.Subscribe(1, 4, DropNewest)
+	id, _ := h.Send(Notification{UserID: 1})
+	sub.Ack(recv(t, sub).ID)
+
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case d := <-sub.C:
+		t.Fatalf("acked notification %d redelivered: %+v", id, d)
+	default:
+	}
+	if s := h.Stats(); s.Acked != 1 || s.Redelivered != 0 {
+		t.Fatalf("stats = %+v", s)
+	}
+}
+
+func TestInboxIsDrainedOnSubscribe(t *testing.T) {
+	h := newTestHub(t, HubOptions{AckTimeout: time.Minute})
+	id, _ := h.Send(Notification{UserID: 1})
+	waitFor(t, "notification to reach the inbox", func() bool { return h.Stats().Stored == 1 })
+
+	sub, _ := h.Subscribe(1, 1, DropNewest)
+	if d := recv(t, sub); d.ID != id {
+		t.Fatalf("got notification %d, want %d", d.ID, id)
+	}
+}
+
+func TestClosingLastSubscriptionStoresUnacked(t *testing.T) {
+	inbox := NewMemoryInbox()
+	h := newTestHub(t, HubOptions{AckTimeout: time.Minute, Inbox: inbox})
+	sub, _ := h.Subscribe(1, 1, DropNewest)
+	id, _ := h.Send(Notification{UserID: 1})
+	recv(t, sub)
+	sub.Close()
+
+	stored := inbox.Drain(1)
+	if len(stored) != 1 || stored[0].ID != id {
+		t.Fatalf("inbox = %+v, want notification %d", stored, id)
+	}
+}
+
+func TestCloseStoresQueuedAndUnacked(t *testing.T) {
+	inbox := NewMemoryInbox()
+	h := NewHub(HubOptions{AckTimeout: time.Minute, Inbox: inbox})
+	sub, _ := h.Subscribe(1, 1, DropNewest)
+	id, _ := h.Send(Notification{UserID: 1})
+	recv(t, sub)
+	h.Close()
+
+	stored := inbox.Drain(1)
+	if len(stored) != 1 || stored[0].ID != id {
+		t.Fatalf("inbox = %+v, want notification %d", stored, id)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Fatal("subscription channel still open after Close")
+	}
+}
+
+func TestCloseLeavesBacklogInInbox(t *testing.T) {
+	inbox := NewMemoryInbox()
+	h := NewHub(HubOptions{AckTimeout: time.Minute, BlockTimeout: time.Minute, Inbox: inbox})
+	h.Subscribe(1, 0, Block) // never read, so dispatch stalls on it
+	sub2, _ := h.Subscribe(2, 8, DropNewest)
+
+	h.Send(Notification{UserID: 1})
+	time.Sleep(50 * time.Millisecond)
+	h.Send(Notification{UserID: 2})
+	h.Send(Notification{UserID: 2})
+	h.Close()
+
+	for d := range sub2.C {
+		t.Errorf("notification %d delivered after Close", d.ID)
+	}
+	if got := len(inbox.Drain(2)); got != 2 {
+		t.Errorf("inbox holds %d notifications for user 2, want 2", got)
+	}
+	if got := len(inbox.Drain(1)); got != 1 {
+		t.Errorf("inbox holds %d notifications for user 1, want 1", got)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"sort"
+	"sync"
+)
+
+// Inbox stores notifications for users who are offline, or who never
+// acknowledged them, until they subscribe again.
+type Inbox interface {
+	Store(n Notification)
+	Drain(userID int) []Notification
+}
+
+// MemoryInbox is an Inbox kept in process memory.
+type MemoryInbox struct {
+	mu    sync.Mutex
+	items map[int][]Notification
+}
+
+func NewMemoryInbox() *MemoryInbox {
+	return &MemoryInbox{items: make(map[int][]Notification)}
+}
+
+func (b *MemoryInbox) Store(n Notification) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.items[n.UserID] = append(b.items[n.UserID], n)
+}
+
+// Drain removes and returns userID's stored notifications, highest
+// priority first and oldest first within a priority.
+func (b *MemoryInbox) Drain(userID int) []Notification {
+	b.mu.Lock()
+	items := b.items[userID]
+	delete(b.items, userID)
+	b.mu.Unlock()
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Priority > items[j].Priority
+	})
+	return items
+}
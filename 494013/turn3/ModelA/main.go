@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+func listenForNotifications(wg *sync.WaitGroup, sub *Subscription, ack func(Delivery) bool) {
+	defer wg.Done()
+	for d := range sub.C {
+		fmt.Printf("User %d received #%d (priority %d, attempt %d): %s %v\n",
+			sub.UserID, d.ID, d.Priority, d.Attempt, d.Type, d.Data)
+		if ack(d) {
+			sub.Ack(d.ID)
+		}
+	}
+}
+
+func main() {
+	hub := NewHub(HubOptions{AckTimeout: 200 * time.Millisecond, MaxAttempts: 3})
+	defer hub.Close()
+
+	hub.AddToGroup("group1", 1)
+	hub.AddToGroup("group1", 2)
+	hub.AddToGroup("group2", 3)
+
+	// User 3 is offline: these wait in the inbox.
+	hub.Send(Notification{UserID: 3, Type: "Reminder", Data: "Don't forget to do something!", Priority: 2})
+	hub.SendGroup("group2", Notification{Type: "Broadcast", Data: "Group 2 announcement!", Priority: 3})
+
+	sub1, _ := hub.Subscribe(1, 8, DropOldest)
+	sub2, _ := hub.Subscribe(2, 1, Block)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go listenForNotifications(&wg, sub1, func(Delivery) bool { return true })
+	// User 2 ignores the first attempt of everything, so it is redelivered.
+	go listenForNotifications(&wg, sub2, func(d Delivery) bool { return d.Attempt > 1 })
+
+	hub.Send(Notification{UserID: 1, Type: "Update", Data: "New data available!", Priority: 1})
+	hub.SendGroup("group1", Notification{Type: "Broadcast", Data: "Group 1 announcement!", Priority: 3})
+
+	time.Sleep(500 * time.Millisecond)
+
+	// User 3 reconnects and receives the missed notifications, highest
+	// priority first.
+	sub3, _ := hub.Subscribe(3, 8, DropNewest)
+	wg.Add(1)
+	go listenForNotifications(&wg, sub3, func(Delivery) bool { return true })
+
+	time.Sleep(300 * time.Millisecond)
+	hub.Close()
+	wg.Wait()
+
+	s := hub.Stats()
+	fmt.Printf("sent=%d delivered=%d acked=%d redelivered=%d dropped=%d stored=%d\n",
+		s.Sent, s.Delivered, s.Acked, s.Redelivered, s.Dropped, s.Stored)
+}
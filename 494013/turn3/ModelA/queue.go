@@ -0,0 +1,37 @@
+package main
+
+import "container/heap"
+
+// queued is a notification waiting in the priority queue. seq breaks ties
+// so that equal priorities are delivered in the order they were sent.
+type queued struct {
+	n       Notification
+	attempt int
+	seq     uint64
+}
+
+// priorityQueue is a max-heap on Priority, FIFO within a priority.
+type priorityQueue []*queued
+
+func (pq priorityQueue) Len() int { return len(pq) }
+
+func (pq priorityQueue) Less(i, j int) bool {
+	if pq[i].n.Priority != pq[j].n.Priority {
+		return pq[i].n.Priority > pq[j].n.Priority
+	}
+	return pq[i].seq < pq[j].seq
+}
+
+func (pq priorityQueue) Swap(i, j int) { pq[i], pq[j] = pq[j], pq[i] }
+
+func (pq *priorityQueue) Push(x interface{}) { *pq = append(*pq, x.(*queued)) }
+
+func (pq *priorityQueue) Pop() interface{} {
+	old := *pq
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*pq = old[:len(old)-1]
+	return item
+}
+
+var _ heap.Interface = (*priorityQueue)(nil)
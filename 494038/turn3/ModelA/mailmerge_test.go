@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTP is a minimal stand-in SMTP server that records each message.
+type fakeSMTP struct {
+	ln   net.Listener
+	mu   sync.Mutex
+	msgs []string
+	rcpt []string
+}
+
+func startFakeSMTP(t *testing.T) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{ln: ln}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeSMTP) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost fake SMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.mu.Lock()
+			s.rcpt = append(s.rcpt, strings.TrimSpace(line[len("RCPT TO:"):]))
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var msg strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				msg.WriteString(l)
+			}
+			s.mu.Lock()
+			s.msgs = append(s.msgs, msg.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestMailMergeDeliversOverSMTP(t *testing.T) {
+	srv := startFakeSMTP(t)
+	tmpl, err := getTemplateCompiled("templates", "thankyou")
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := &mail.Address{Name: "Sender", Address: "sender@example.com"}
+	recipients := []Recipient{
+		{"Name": "Zoë", "Email": "zoe@example.com", "Gift": "a mug"},
+		{"Name": "Charlie", "Email": "charlie@example.com", "Gift": ""},
+		{"Name": "NoMail", "Gift": "a pen"},
+		{"Name": "Bob", "Email": "bob@example.com", "Gift": "a book"},
+	}
+
+	out := &smtpOutput{addr: srv.ln.Addr().String(), from: from.Address}
+	sent, failures := mailMerge(tmpl, from, recipients, out, "Sender")
+
+	if sent != 2 || len(failures) != 2 {
+		t.Fatalf("sent=%d failures=%v, want 2 sent and 2 failures", sent, failures)
+	}
+	if !strings.Contains(failures[0].Error(), "Gift") {
+		t.Errorf("failure for blank field = %v, want it to name Gift", failures[0])
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if got := strings.Join(srv.rcpt, ","); got != "<zoe@example.com>,<bob@example.com>" {
+		t.Errorf("RCPT TO = %s", got)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(srv.msgs[0]))
+	if err != nil {
+		t.Fatalf("message is not RFC 5322: %v", err)
+	}
+	to, err := msg.Header.AddressList("To")
+	if err != nil || to[0].Name != "Zoë" {
+		t.Errorf("To = %v (%v), want decoded name Zoë", to, err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Thank you for a mug, Zoë!" {
+		t.Errorf("Subject = %q (%v)", subject, err)
+	}
+	if ct := msg.Header.Get("Content-Type"); !strings.HasPrefix(ct, "multipart/alternative;") {
+		t.Errorf("Content-Type = %q, want multipart/alternative", ct)
+	}
+}
+
+func TestWriteMboxQuotesFromLines(t *testing.T) {
+	var b strings.Builder
+	msg := []byte("Subject: hi\r\n\r\nFrom the start\r\n>From quoted\r\n")
+	if err := writeMbox(&b, "me@example.com", testDate, msg); err != nil {
+		t.Fatal(err)
+	}
+	want := "From me@example.com Thu Jan  1 00:00:00 2026\nSubject: hi\n\n>From the start\n>>From quoted\n\n"
+	if b.String() != want {
+		t.Errorf("mbox = %q, want %q", b.String(), want)
+	}
+}
+
+func TestReadJSONRecipientsKeepsNumbers(t *testing.T) {
+	in := `[{"Name": "Ann", "Account": 1234567, "Balance": 12.50, "VIP": true, "Note": null}]`
+	got, err := readJSONRecipients(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Recipient{"Name": "Ann", "Account": "1234567", "Balance": "12.50", "VIP": "true"}
+	if len(got) != 1 || len(got[0]) != len(want) {
+		t.Fatalf("recipients = %v, want [%v]", got, want)
+	}
+	for k, v := range want {
+		if got[0][k] != v {
+			t.Errorf("%s = %q, want %q", k, got[0][k], v)
+		}
+	}
+}
+
+var testDate = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
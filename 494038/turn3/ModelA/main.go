@@ -0,0 +1,162 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// Output receives each rendered message; implementations write .eml
+// files, an mbox, or hand the message to an SMTP server.
+type Output interface {
+	Write(to *mail.Address, msg []byte) error
+	Close() error
+}
+
+type emlDir struct {
+	dir string
+	n   int
+}
+
+var unsafeFilename = regexp.MustCompile(`[^A-Za-z0-9._@-]+`)
+
+func (o *emlDir) Write(to *mail.Address, msg []byte) error {
+	o.n++
+	name := fmt.Sprintf("%03d-%s.eml", o.n, unsafeFilename.ReplaceAllString(to.Address, "_"))
+	return os.WriteFile(filepath.Join(o.dir, name), msg, 0o644)
+}
+
+func (o *emlDir) Close() error { return nil }
+
+type mbox struct {
+	f    *os.File
+	from string
+}
+
+func (o *mbox) Write(to *mail.Address, msg []byte) error {
+	return writeMbox(o.f, o.from, time.Now(), msg)
+}
+
+func (o *mbox) Close() error { return o.f.Close() }
+
+type smtpOutput struct {
+	addr string
+	from string
+}
+
+func (o *smtpOutput) Write(to *mail.Address, msg []byte) error {
+	return smtp.SendMail(o.addr, nil, o.from, []string{to.Address}, msg)
+}
+
+func (o *smtpOutput) Close() error { return nil }
+
+type stdout struct{}
+
+func (stdout) Write(to *mail.Address, msg []byte) error {
+	fmt.Printf("%s\n", msg)
+	return nil
+}
+
+func (stdout) Close() error { return nil }
+
+// mailMerge renders and writes one message per recipient. A recipient
+// that fails is reported and skipped; the rest of the batch still goes out.
+func mailMerge(tmpl *Templates, from *mail.Address, recipients []Recipient, out Output, sender string) (sent int, failures []error) {
+	for i, rec := range recipients {
+		fail := func(err error) {
+			failures = append(failures, fmt.Errorf("recipient %d (%s): %w", i+1, rec.Email(), err))
+		}
+
+		to, err := mail.ParseAddress(rec.Email())
+		if err != nil {
+			fail(fmt.Errorf("invalid email: %w", err))
+			continue
+		}
+		to.Name = rec.Name()
+
+		data := map[string]string{"Sender": sender}
+		for k, v := range rec {
+			data[k] = v
+		}
+		rendered, err := tmpl.Render(data)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		msg, err := buildMessage(from, to, rendered, time.Now())
+		if err != nil {
+			fail(err)
+			continue
+		}
+		if err := out.Write(to, msg); err != nil {
+			fail(err)
+			continue
+		}
+		sent++
+	}
+	return sent, failures
+}
+
+func main() {
+	recipientsPath := flag.String("recipients", "recipients.csv", "recipients file (.csv or .json)")
+	templateDir := flag.String("templates", "templates", "template directory")
+	templateName := flag.String("template", "thankyou", "template base name")
+	fromFlag := flag.String("from", "Your Name <you@example.com>", "From address")
+	outDir := flag.String("out", "", "write one .eml file per recipient into this directory")
+	mboxPath := flag.String("mbox", "", "append messages to this mbox file")
+	smtpAddr := flag.String("smtp", "", "deliver through this SMTP server (host:port)")
+	flag.Parse()
+
+	from, err := mail.ParseAddress(*fromFlag)
+	if err != nil {
+		log.Fatalf("Invalid -from address: %v", err)
+	}
+	tmpl, err := getTemplateCompiled(*templateDir, *templateName)
+	if err != nil {
+		log.Fatalf("Error compiling template: %v", err)
+	}
+	recipients, err := loadRecipients(*recipientsPath)
+	if err != nil {
+		log.Fatalf("Error loading recipients: %v", err)
+	}
+
+	var out Output = stdout{}
+	switch {
+	case *smtpAddr != "":
+		out = &smtpOutput{addr: *smtpAddr, from: from.Address}
+	case *mboxPath != "":
+		f, err := os.OpenFile(*mboxPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			log.Fatalf("Error opening mbox: %v", err)
+		}
+		out = &mbox{f: f, from: from.Address}
+	case *outDir != "":
+		if err := os.MkdirAll(*outDir, 0o755); err != nil {
+			log.Fatalf("Error creating output directory: %v", err)
+		}
+		out = &emlDir{dir: *outDir}
+	}
+
+	sender := from.Name
+	if sender == "" {
+		sender = from.Address
+	}
+	sent, failures := mailMerge(tmpl, from, recipients, out, sender)
+	if err := out.Close(); err != nil {
+		log.Printf("Error closing output: %v", err)
+	}
+
+	for _, err := range failures {
+		log.Printf("Skipped %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "%d sent, %d failed\n", sent, len(failures))
+	if len(failures) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Recipient holds one row of merge data. Email and Name are used for the
+// To header; every field is available to the templates.
+type Recipient map[string]string
+
+func (r Recipient) Email() string { return r["Email"] }
+func (r Recipient) Name() string  { return r["Name"] }
+
+// loadRecipients reads a .csv file with a header row or a .json array of
+// objects.
+func loadRecipients(path string) ([]Recipient, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readCSVRecipients(f)
+	case ".json":
+		return readJSONRecipients(f)
+	}
+	return nil, fmt.Errorf("unsupported recipient file %q: want .csv or .json", path)
+}
+
+func readCSVRecipients(r io.Reader) ([]Recipient, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	header := rows[0]
+	out := make([]Recipient, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		rec := make(Recipient, len(header))
+		for i, key := range header {
+			if i < len(row) {
+				rec[strings.TrimSpace(key)] = strings.TrimSpace(row[i])
+			}
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+func readJSONRecipients(r io.Reader) ([]Recipient, error) {
+	var raw []map[string]interface{}
+	dec := json.NewDecoder(r)
+	// UseNumber keeps numbers as written, so a zip code or an account ID
+	// doesn't come out as 1.2345e+06.
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	out := make([]Recipient, 0, len(raw))
+	for _, obj := range raw {
+		rec := make(Recipient, len(obj))
+		for k, v := range obj {
+			switch v := v.(type) {
+			case nil:
+			case json.Number:
+				rec[k] = v.String()
+			default:
+				rec[k] = fmt.Sprint(v)
+			}
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
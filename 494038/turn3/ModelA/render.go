@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// Templates is the compiled set for one message: a subject line and text
+// and HTML bodies. The HTML body is optional.
+type Templates struct {
+	Subject *template.Template
+	Text    *template.Template
+	HTML    *htmltemplate.Template
+}
+
+// Rendered is a message ready to be encoded.
+type Rendered struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// getTemplateCompiled loads <dir>/<name>.subject.txt, <name>.txt and, if it
+// exists, <name>.html. Templates fail on fields the data doesn't provide.
+func getTemplateCompiled(dir, name string) (*Templates, error) {
+	read := func(suffix string) (string, error) {
+		b, err := os.ReadFile(filepath.Join(dir, name+suffix))
+		return string(b), err
+	}
+
+	subject, err := read(".subject.txt")
+	if err != nil {
+		return nil, err
+	}
+	text, err := read(".txt")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Templates{}
+	if t.Subject, err = template.New("subject").Option("missingkey=error").Parse(strings.TrimSpace(subject)); err != nil {
+		return nil, err
+	}
+	if t.Text, err = template.New("text").Option("missingkey=error").Parse(text); err != nil {
+		return nil, err
+	}
+	if html, err := read(".html"); err == nil {
+		if t.HTML, err = htmltemplate.New("html").Option("missingkey=error").Parse(html); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Render executes every template for one recipient. Empty fields count as
+// missing, so a blank CSV cell fails the recipient instead of producing
+// "your gift of ."
+func (t *Templates) Render(data map[string]string) (Rendered, error) {
+	present := make(map[string]string, len(data))
+	for k, v := range data {
+		if v != "" {
+			present[k] = v
+		}
+	}
+
+	var r Rendered
+	var buf bytes.Buffer
+	if err := t.Subject.Execute(&buf, present); err != nil {
+		return r, err
+	}
+	r.Subject = buf.String()
+
+	buf.Reset()
+	if err := t.Text.Execute(&buf, present); err != nil {
+		return r, err
+	}
+	r.Text = buf.String()
+
+	if t.HTML != nil {
+		buf.Reset()
+		if err := t.HTML.Execute(&buf, present); err != nil {
+			return r, err
+		}
+		r.HTML = buf.String()
+	}
+	return r, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"time"
+)
+
+var sampleNames = []string{"Alice", "Zoë", "山田太郎", "Ngô Bảo Châu", "김민준", "Chloé 👩‍💻", "Bob 🇯🇵", "Müller-Lüdenscheidt Johann"}
+var sampleCities = []string{"Paris", "東京", "서울", "São Paulo", "Zürich", "Hà Nội"}
+
+func main() {
+	n := flag.Int("rows", 100000, "number of rows to generate")
+	show := flag.Int("show", 12, "rows to print to stdout")
+	out := flag.String("out", "", "write the full table to this file")
+	flag.Parse()
+
+	rows := make([][]string, *n)
+	for i := range rows {
+		rows[i] = []string{
+			fmt.Sprint(i),
+			sampleNames[i%len(sampleNames)],
+			sampleCities[i%len(sampleCities)],
+		}
+	}
+
+	table := &Table{
+		Columns: []Column{
+			{Header: "ID", Align: AlignRight},
+			{Header: "Name", MaxWidth: 16},
+			{Header: "City", Align: AlignCenter},
+		},
+		Separator: " | ",
+		Ellipsis:  "…",
+		Workers:   runtime.NumCPU(),
+		ChunkSize: 2048,
+	}
+
+	if err := table.Render(os.Stdout, rows[:min(max(*show, 0), len(rows))]); err != nil {
+		log.Fatal(err)
+	}
+
+	var w io.Writer = io.Discard
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		bw := bufio.NewWriter(f)
+		defer bw.Flush()
+		w = bw
+	}
+
+	start := time.Now()
+	if err := table.Render(w, rows); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\nRendered %d rows in %s\n", len(rows), time.Since(start))
+}
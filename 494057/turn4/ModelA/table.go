@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+)
+
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+	AlignCenter
+)
+
+type Column struct {
+	Header   string
+	Align    Align
+	MaxWidth int // 0 means unlimited
+}
+
+// Table formats rows of cells into aligned columns. Widths are measured in
+// terminal cells, not bytes, so CJK text and emoji line up.
+type Table struct {
+	Columns   []Column
+	Separator string
+	Ellipsis  string
+	Workers   int
+	ChunkSize int
+}
+
+// Widths returns the display width of every column over the header and
+// rows, capped at each column's MaxWidth.
+func (t *Table) Widths(rows [][]string) []int {
+	widths := make([]int, len(t.Columns))
+	for i, c := range t.Columns {
+		widths[i] = StringWidth(c.Header)
+	}
+	for _, row := range rows {
+		for i := range t.Columns {
+			if i < len(row) {
+				if w := StringWidth(row[i]); w > widths[i] {
+					widths[i] = w
+				}
+			}
+		}
+	}
+	for i, c := range t.Columns {
+		if c.MaxWidth > 0 && widths[i] > c.MaxWidth {
+			widths[i] = c.MaxWidth
+		}
+	}
+	return widths
+}
+
+// formatRow appends one formatted line to buf.
+func (t *Table) formatRow(buf *bytes.Buffer, row []string, widths []int) {
+	for i, c := range t.Columns {
+		if i > 0 {
+			buf.WriteString(t.Separator)
+		}
+		cell := ""
+		if i < len(row) {
+			cell = row[i]
+		}
+		cell = Truncate(cell, widths[i], t.Ellipsis)
+		pad := widths[i] - StringWidth(cell)
+		last := i == len(t.Columns)-1
+
+		switch c.Align {
+		case AlignRight:
+			writeSpaces(buf, pad)
+			buf.WriteString(cell)
+		case AlignCenter:
+			writeSpaces(buf, pad/2)
+			buf.WriteString(cell)
+			if !last {
+				writeSpaces(buf, pad-pad/2)
+			}
+		default:
+			buf.WriteString(cell)
+			if !last {
+				writeSpaces(buf, pad)
+			}
+		}
+	}
+	buf.WriteByte('\n')
+}
+
+const spaces = "                                                                "
+
+func writeSpaces(buf *bytes.Buffer, n int) {
+	for n > len(spaces) {
+		buf.WriteString(spaces)
+		n -= len(spaces)
+	}
+	if n > 0 {
+		buf.WriteString(spaces[:n])
+	}
+}
+
+// header returns the header line and a rule underneath it.
+func (t *Table) header(widths []int) []byte {
+	var buf bytes.Buffer
+	headers := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		headers[i] = c.Header
+	}
+	t.formatRow(&buf, headers, widths)
+	for i, w := range widths {
+		if i > 0 {
+			buf.WriteString(strings.Repeat("-", StringWidth(t.Separator)))
+		}
+		buf.WriteString(strings.Repeat("-", w))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+type chunkJob struct {
+	rows [][]string
+	out  chan []byte
+}
+
+// Render writes the table to w. Chunks of rows are formatted in parallel
+// but written strictly in input order, and only a bounded number of
+// formatted chunks are held in memory at once.
+func (t *Table) Render(w io.Writer, rows [][]string) error {
+	widths := t.Widths(rows)
+	if _, err := w.Write(t.header(widths)); err != nil {
+		return err
+	}
+
+	workers := t.Workers
+	if workers <= 0 {
+		workers = 4
+	}
+	chunkSize := t.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 1024
+	}
+
+	jobs := make(chan chunkJob)
+	// ordered holds one result channel per chunk, in input order. Its
+	// capacity is the window of chunks that may be in flight.
+	ordered := make(chan chan []byte, workers*2)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var buf bytes.Buffer
+			for job := range jobs {
+				buf.Reset()
+				for _, row := range job.rows {
+					t.formatRow(&buf, row, widths)
+				}
+				job.out <- append([]byte(nil), buf.Bytes()...)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(ordered)
+		defer close(jobs)
+		for start := 0; start < len(rows); start += chunkSize {
+			end := start + chunkSize
+			if end > len(rows) {
+				end = len(rows)
+			}
+			out := make(chan []byte, 1)
+			select {
+			case ordered <- out:
+			case <-done:
+				return
+			}
+			select {
+			case jobs <- chunkJob{rows: rows[start:end], out: out}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var err error
+	for out := range ordered {
+		if err != nil {
+			// Drain without waiting: chunks queued after the failure may
+			// never have been handed to a worker.
+			continue
+		}
+		if _, err = w.Write(<-out); err != nil {
+			close(done)
+		}
+	}
+	wg.Wait()
+	return err
+}
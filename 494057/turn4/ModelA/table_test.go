@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRenderAlignsWideAndCombiningText(t *testing.T) {
+	table := &Table{
+		Columns: []Column{
+			{Header: "Name", MaxWidth: 4},
+			{Header: "City", Align: AlignCenter},
+			{Header: "N", Align: AlignRight},
+		},
+		Separator: " | ",
+		Ellipsis:  "…",
+	}
+	rows := [][]string{
+		{"山田", "東", "1"},
+		{"e\u0301", "Zoe\u0308", "22"},
+		{"山田太郎", "", "333"},
+	}
+
+	var buf bytes.Buffer
+	if err := table.Render(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"Name | City |   N\n" +
+		"-----------------\n" +
+		"山田 |  東  |   1\n" +
+		"e\u0301    | Zoe\u0308  |  22\n" +
+		"山…  |      | 333\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Render output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRenderKeepsRowOrderAcrossChunks(t *testing.T) {
+	table := &Table{
+		Columns:   []Column{{Header: "ID", Align: AlignRight}, {Header: "Name"}},
+		Separator: " ",
+		Workers:   3,
+		ChunkSize: 7,
+	}
+	names := []string{"Zoë", "山田太郎", "Chloé 👩‍💻", "Bob 🇯🇵"}
+	rows := make([][]string, 500)
+	for i := range rows {
+		rows[i] = []string{fmt.Sprint(i), names[i%len(names)]}
+	}
+
+	var buf bytes.Buffer
+	if err := table.Render(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(rows)+2 {
+		t.Fatalf("got %d lines, want %d", len(lines), len(rows)+2)
+	}
+	for i, line := range lines[2:] {
+		if want := fmt.Sprintf("%3d %s", i, rows[i][1]); strings.TrimRight(line, " ") != want {
+			t.Fatalf("line %d = %q, want %q", i, line, want)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// interval is an inclusive range of code points.
+type interval struct{ lo, hi rune }
+
+// wideRanges lists East Asian Wide (W) and Fullwidth (F) blocks plus the
+// emoji blocks terminals render two cells wide. It is a condensed version
+// of Unicode's EastAsianWidth.txt covering the common cases.
+var wideRanges = []interval{
+	{0x1100, 0x115F},   // Hangul Jamo initial consonants
+	{0x231A, 0x231B},   // watch, hourglass
+	{0x2329, 0x232A},   // angle brackets
+	{0x23E9, 0x23EC},   // media controls
+	{0x23F0, 0x23F0},   // alarm clock
+	{0x23F3, 0x23F3},   // hourglass flowing
+	{0x25FD, 0x25FE},   // small squares
+	{0x2614, 0x2615},   // umbrella, hot beverage
+	{0x2648, 0x2653},   // zodiac
+	{0x267F, 0x267F},   // wheelchair
+	{0x2693, 0x2693},   // anchor
+	{0x26A1, 0x26A1},   // high voltage
+	{0x26AA, 0x26AB},   // circles
+	{0x26BD, 0x26BE},   // soccer, baseball
+	{0x26C4, 0x26C5},   // snowman, sun behind cloud
+	{0x26CE, 0x26CE},   // ophiuchus
+	{0x26D4, 0x26D4},   // no entry
+	{0x26EA, 0x26EA},   // church
+	{0x26F2, 0x26F3},   // fountain, golf
+	{0x26F5, 0x26F5},   // sailboat
+	{0x26FA, 0x26FA},   // tent
+	{0x26FD, 0x26FD},   // fuel pump
+	{0x2705, 0x2705},   // check mark button
+	{0x270A, 0x270B},   // fists
+	{0x2728, 0x2728},   // sparkles
+	{0x274C, 0x274C},   // cross mark
+	{0x274E, 0x274E},   // cross mark button
+	{0x2753, 0x2755},   // question marks
+	{0x2757, 0x2757},   // exclamation mark
+	{0x2795, 0x2797},   // math symbols
+	{0x27B0, 0x27B0},   // curly loop
+	{0x27BF, 0x27BF},   // double curly loop
+	{0x2B1B, 0x2B1C},   // large squares
+	{0x2B50, 0x2B50},   // star
+	{0x2B55, 0x2B55},   // circle
+	{0x2E80, 0x303E},   // CJK radicals, Kangxi, CJK symbols and punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, Bopomofo, CJK compatibility
+	{0x3400, 0x4DBF},   // CJK Extension A
+	{0x4E00, 0x9FFF},   // CJK Unified Ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xA960, 0xA97F},   // Hangul Jamo Extended-A
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE10, 0xFE19},   // vertical forms
+	{0xFE30, 0xFE6F},   // CJK compatibility forms, small forms
+	{0xFF00, 0xFF60},   // fullwidth forms
+	{0xFFE0, 0xFFE6},   // fullwidth signs
+	{0x16FE0, 0x16FE4}, // ideographic symbols
+	{0x17000, 0x18AFF}, // Tangut
+	{0x1B000, 0x1B16F}, // Kana supplement and extended
+	{0x1F004, 0x1F004}, // mahjong tile
+	{0x1F0CF, 0x1F0CF}, // playing card
+	{0x1F18E, 0x1F18E}, // AB button
+	{0x1F191, 0x1F19A}, // squared words
+	{0x1F1E6, 0x1F1FF}, // regional indicators (flags)
+	{0x1F200, 0x1F251}, // enclosed ideographic supplement
+	{0x1F300, 0x1F64F}, // misc symbols and pictographs, emoticons
+	{0x1F680, 0x1F6FF}, // transport and map symbols
+	{0x1F7E0, 0x1F7EB}, // colored circles and squares
+	{0x1F90C, 0x1F9FF}, // supplemental symbols and pictographs
+	{0x1FA70, 0x1FAFF}, // symbols and pictographs extended-A
+	{0x20000, 0x2FFFD}, // CJK Extension B and later
+	{0x30000, 0x3FFFD}, // CJK Extension G and later
+}
+
+const (
+	zeroWidthJoiner = '\u200D'
+	variationSel16  = '\uFE0F'
+)
+
+func inRanges(r rune, ranges []interval) bool {
+	lo, hi := 0, len(ranges)-1
+	for lo <= hi {
+		mid := (lo + hi) / 2
+		switch {
+		case r < ranges[mid].lo:
+			hi = mid - 1
+		case r > ranges[mid].hi:
+			lo = mid + 1
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// runeWidth returns the number of terminal cells r occupies on its own.
+func runeWidth(r rune) int {
+	switch {
+	case r == 0:
+		return 0
+	case r < 0x20 || (r >= 0x7F && r < 0xA0):
+		return 0
+	case r < 0x300:
+		return 1
+	case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), unicode.Is(unicode.Cf, r):
+		return 0
+	case r >= 0xFE00 && r <= 0xFE0F: // variation selectors
+		return 0
+	case r >= 0x1F3FB && r <= 0x1F3FF: // skin tone modifiers
+		return 0
+	case inRanges(r, wideRanges):
+		return 2
+	}
+	return 1
+}
+
+// cluster describes the next user-perceived character of s: its byte
+// length and display width. Emoji joined with ZWJ, skin tones and
+// variation selectors, and combining marks stay with their base, and a
+// pair of regional indicators forms one flag.
+func cluster(s string) (size, width int) {
+	r, n := utf8.DecodeRuneInString(s)
+	size, width = n, runeWidth(r)
+	regional := r >= 0x1F1E6 && r <= 0x1F1FF
+	for size < len(s) {
+		next, n := utf8.DecodeRuneInString(s[size:])
+		switch {
+		case next == zeroWidthJoiner:
+			size += n
+			if size < len(s) {
+				_, n = utf8.DecodeRuneInString(s[size:])
+				size += n
+			}
+		case next == variationSel16:
+			size += n
+			if width == 1 {
+				width = 2 // text symbol promoted to emoji presentation
+			}
+		case regional && next >= 0x1F1E6 && next <= 0x1F1FF:
+			size += n
+			regional = false
+		case runeWidth(next) == 0:
+			size += n
+		default:
+			return size, width
+		}
+	}
+	return size, width
+}
+
+// StringWidth returns the display width of s in terminal cells.
+func StringWidth(s string) int {
+	width := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c >= 0x20 && c < 0x7F && (i+1 == len(s) || s[i+1] < 0x80) {
+			// ASCII fast path.
+			width++
+			i++
+			continue
+		}
+		n, w := cluster(s[i:])
+		width += w
+		i += n
+	}
+	return width
+}
+
+// Truncate shortens s to at most max cells, ending with ellipsis when
+// anything was cut. It never splits a character cluster, so the result
+// can be a cell narrower than max.
+func Truncate(s string, max int, ellipsis string) string {
+	if StringWidth(s) <= max {
+		return s
+	}
+	room := max - StringWidth(ellipsis)
+	if room < 0 {
+		return ""
+	}
+	var b strings.Builder
+	width := 0
+	for i := 0; i < len(s); {
+		n, w := cluster(s[i:])
+		if width+w > room {
+			break
+		}
+		b.WriteString(s[i : i+n])
+		width += w
+		i += n
+	}
+	b.WriteString(ellipsis)
+	return b.String()
+}
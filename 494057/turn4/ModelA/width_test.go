@@ -0,0 +1,58 @@
+package main
+
+import "testing"
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"Alice", 5},
+		{"Zoë", 3},
+		{"Zoe\u0308", 3},           // combining diaeresis
+		{"Ngo\u0302 Ba\u0309o", 7}, // combining circumflex and hook
+		{"山田太郎", 8},
+		{"김민준", 6},
+		{"ｶﾅ", 2}, // halfwidth katakana stay narrow
+		{"Ａ", 2},  // fullwidth Latin
+		{"👩‍💻", 2},
+		{"👍🏽", 2},
+		{"🇯🇵", 2},
+		{"❤️", 2},
+		{"Chloé 👩‍💻", 8},
+		{"a\tb", 2},
+	}
+	for _, tt := range tests {
+		if got := StringWidth(tt.s); got != tt.want {
+			t.Errorf("StringWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"Alice", 5, "Alice"},
+		{"Alice", 4, "Ali…"},
+		{"山田太郎", 8, "山田太郎"},
+		{"山田太郎", 5, "山田…"},
+		{"山田太郎", 4, "山…"}, // a wide rune never straddles the limit
+		{"e\u0301e\u0301e\u0301", 2, "e\u0301…"},
+		{"👩‍💻👩‍💻", 3, "👩‍💻…"},
+		{"🇯🇵🇫🇷", 3, "🇯🇵…"},
+		{"abc", 0, ""},
+	}
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.max, "…")
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want)
+		}
+		if w := StringWidth(got); w > tt.max {
+			t.Errorf("Truncate(%q, %d) is %d cells wide", tt.s, tt.max, w)
+		}
+	}
+}
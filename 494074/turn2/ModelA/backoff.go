@@ -0,0 +1,87 @@
+package retry
+
+import (
+	"math"
+	"time"
+)
+
+// Backoff computes the delay before the next attempt. attempt is the
+// number of attempts made so far (1 after the first failure), prev is the
+// previous delay (0 the first time) and rnd returns a value in [0, 1).
+type Backoff interface {
+	Delay(attempt int, prev time.Duration, rnd func() float64) time.Duration
+}
+
+// Constant waits the same duration between every attempt.
+type Constant time.Duration
+
+func (c Constant) Delay(int, time.Duration, func() float64) time.Duration {
+	return time.Duration(c)
+}
+
+// Exponential waits Initial, Initial*Multiplier, Initial*Multiplier², ...
+// up to Max, or the largest Duration when Max is unset. With Jitter set to
+// a fraction f, each delay is spread uniformly over [d*(1-f), d].
+type Exponential struct {
+	Initial    time.Duration
+	Multiplier float64 // defaults to 2
+	Max        time.Duration
+	Jitter     float64
+}
+
+func (e Exponential) Delay(attempt int, _ time.Duration, rnd func() float64) time.Duration {
+	mult := e.Multiplier
+	if mult <= 0 {
+		mult = 2
+	}
+	if e.Initial <= 0 {
+		return 0
+	}
+	// Late attempts overflow to +Inf, so cap before jitter and before
+	// converting back to an integer duration.
+	limit := float64(math.MaxInt64)
+	if e.Max > 0 {
+		limit = float64(e.Max)
+	}
+	d := math.Min(float64(e.Initial)*math.Pow(mult, float64(attempt-1)), limit)
+	if e.Jitter > 0 {
+		d -= d * e.Jitter * rnd()
+	}
+	if d >= float64(math.MaxInt64) {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// DecorrelatedJitter is the "decorrelated jitter" schedule: each delay is
+// random between Base and three times the previous delay, capped at Max,
+// or the largest Duration when Max is unset. It spreads out clients that
+// failed together better than plain jitter.
+type DecorrelatedJitter struct {
+	Base time.Duration // defaults to 100ms
+	Max  time.Duration
+}
+
+func (d DecorrelatedJitter) Delay(_ int, prev time.Duration, rnd func() float64) time.Duration {
+	base := d.Base
+	if base <= 0 {
+		base = 100 * time.Millisecond
+	}
+	limit := time.Duration(math.MaxInt64)
+	if d.Max > 0 {
+		limit = d.Max
+	}
+	prev = max(prev, base)
+	// Clamp before multiplying: 3*prev overflows once prev passes a third
+	// of the largest Duration.
+	upper := time.Duration(math.MaxInt64)
+	if prev < upper/3 {
+		upper = 3 * prev
+	}
+	delay := base + time.Duration(rnd()*float64(upper-base))
+	if delay < base || delay > limit {
+		// Past Max, or float rounding stepped past the largest Duration.
+		delay = limit
+	}
+	return delay
+}
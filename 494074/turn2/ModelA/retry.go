@@ -0,0 +1,178 @@
+// Package retry replaces RetryWithBackoff from 494074 with a configurable
+// Policy: pluggable backoff strategies, attempt and elapsed-time limits, a
+// retryable-error classifier, per-attempt timeouts and an OnRetry hook.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Clock abstracts time so tests can verify schedules without sleeping.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Attempt describes a failed attempt that is about to be retried.
+type Attempt struct {
+	Number  int           // 1 for the first attempt
+	Err     error         // the error it returned
+	Delay   time.Duration // wait before the next attempt
+	Elapsed time.Duration // time since the first attempt started
+}
+
+// Policy configures Do. The zero value makes a single attempt.
+type Policy struct {
+	// MaxAttempts caps the total number of attempts, including the
+	// first. Zero means one attempt unless MaxElapsed is set, in which
+	// case only the elapsed-time budget applies.
+	MaxAttempts int
+	// MaxElapsed stops retrying once another wait would exceed it.
+	MaxElapsed time.Duration
+	// Backoff computes delays; nil retries immediately.
+	Backoff Backoff
+	// Retryable reports whether err is worth retrying. nil retries every
+	// error not wrapped with Permanent.
+	Retryable func(error) bool
+	// AttemptTimeout bounds each individual attempt.
+	AttemptTimeout time.Duration
+	// OnRetry is called before each wait, e.g. to record metrics.
+	OnRetry func(Attempt)
+
+	Clock Clock
+	Rand  func() float64
+}
+
+// ExhaustedError is returned when the policy gives up on a retryable
+// error. It unwraps to the last attempt's error.
+type ExhaustedError struct {
+	Attempts int
+	Elapsed  time.Duration
+	Last     error
+}
+
+func (e *ExhaustedError) Error() string {
+	return fmt.Sprintf("retry: gave up after %d attempt(s) in %s: %v", e.Attempts, e.Elapsed.Round(time.Millisecond), e.Last)
+}
+
+func (e *ExhaustedError) Unwrap() error { return e.Last }
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not retryable regardless of the classifier.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// On returns a classifier that retries only errors matching one of
+// targets with errors.Is.
+func On(targets ...error) func(error) bool {
+	return func(err error) bool {
+		for _, t := range targets {
+			if errors.Is(err, t) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Do calls fn until it succeeds, the error is not retryable, the policy's
+// limits are reached or ctx is done. Non-retryable errors are returned as
+// is; exhausted retries return *ExhaustedError.
+func (p Policy) Do(ctx context.Context, fn func(context.Context) error) error {
+	clock := p.Clock
+	if clock == nil {
+		clock = realClock{}
+	}
+	rnd := p.Rand
+	if rnd == nil {
+		rnd = rand.Float64
+	}
+	maxAttempts := p.MaxAttempts
+	if maxAttempts <= 0 && p.MaxElapsed <= 0 {
+		maxAttempts = 1
+	}
+
+	start := clock.Now()
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		err := p.attempt(ctx, fn)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return errors.Join(err, ctx.Err())
+		}
+		var perm permanentError
+		if errors.As(err, &perm) {
+			// Strip the marker only when it is outermost; a caller's
+			// fmt.Errorf wrapping around it is context worth keeping.
+			if top, ok := err.(permanentError); ok {
+				return top.err
+			}
+			return err
+		}
+		if p.Retryable != nil && !p.Retryable(err) {
+			return err
+		}
+
+		elapsed := clock.Now().Sub(start)
+		exhausted := &ExhaustedError{Attempts: attempt, Elapsed: elapsed, Last: err}
+		if maxAttempts > 0 && attempt >= maxAttempts {
+			return exhausted
+		}
+		if p.Backoff != nil {
+			delay = p.Backoff.Delay(attempt, delay, rnd)
+		}
+		if p.MaxElapsed > 0 && elapsed+delay > p.MaxElapsed {
+			return exhausted
+		}
+		if p.OnRetry != nil {
+			p.OnRetry(Attempt{Number: attempt, Err: err, Delay: delay, Elapsed: elapsed})
+		}
+
+		select {
+		case <-clock.After(delay):
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		}
+	}
+}
+
+func (p Policy) attempt(ctx context.Context, fn func(context.Context) error) error {
+	if p.AttemptTimeout <= 0 {
+		return fn(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, p.AttemptTimeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+// DoValue is Do for functions that return a value.
+func DoValue[T any](ctx context.Context, p Policy, fn func(context.Context) (T, error)) (T, error) {
+	var out T
+	err := p.Do(ctx, func(ctx context.Context) error {
+		v, err := fn(ctx)
+		if err == nil {
+			out = v
+		}
+		return err
+	})
+	return out, err
+}
@@ -0,0 +1,248 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeClock never sleeps: After advances the clock and fires at once,
+// recording each requested delay.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+var errTemporary = errors.New("temporary")
+
+func failing(n int, err error) (func(context.Context) error, *int) {
+	calls := 0
+	return func(context.Context) error {
+		calls++
+		if calls <= n {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestBackoffSchedules(t *testing.T) {
+	half := func() float64 { return 0.5 }
+	ms := time.Millisecond
+
+	tests := []struct {
+		name    string
+		backoff Backoff
+		want    []time.Duration
+	}{
+		{"constant", Constant(50 * ms), []time.Duration{50 * ms, 50 * ms, 50 * ms, 50 * ms}},
+		{"exponential capped", Exponential{Initial: 100 * ms, Max: 500 * ms}, []time.Duration{100 * ms, 200 * ms, 400 * ms, 500 * ms}},
+		{"exponential x3 with jitter", Exponential{Initial: 100 * ms, Multiplier: 3, Jitter: 0.5}, []time.Duration{75 * ms, 225 * ms, 675 * ms, 2025 * ms}},
+		// base + 0.5*(3*prev - base): 10+0.5*20, 10+0.5*50, 10+0.5*95, capped.
+		{"decorrelated jitter", DecorrelatedJitter{Base: 10 * ms, Max: 60 * ms}, []time.Duration{20 * ms, 35 * ms, 57500 * time.Microsecond, 60 * ms}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			fn, calls := failing(4, errTemporary)
+			p := Policy{MaxAttempts: 5, Backoff: tt.backoff, Clock: clock, Rand: half}
+
+			if err := p.Do(context.Background(), fn); err != nil {
+				t.Fatalf("Do() = %v", err)
+			}
+			if *calls != 5 {
+				t.Errorf("calls = %d, want 5", *calls)
+			}
+			if !reflect.DeepEqual(clock.sleeps, tt.want) {
+				t.Errorf("sleeps = %v, want %v", clock.sleeps, tt.want)
+			}
+		})
+	}
+}
+
+func TestExponentialDoesNotOverflow(t *testing.T) {
+	zero := func() float64 { return 0 }
+	for _, attempt := range []int{64, 100, 2000} {
+		if d := (Exponential{Initial: time.Second}).Delay(attempt, 0, zero); d != math.MaxInt64 {
+			t.Errorf("uncapped Delay(%d) = %s, want the largest Duration", attempt, d)
+		}
+		if d := (Exponential{Initial: time.Second, Max: time.Minute}).Delay(attempt, 0, zero); d != time.Minute {
+			t.Errorf("capped Delay(%d) = %s, want 1m", attempt, d)
+		}
+		if d := (Exponential{Initial: time.Second, Jitter: 0.5}).Delay(attempt, 0, func() float64 { return 0.5 }); d <= 0 {
+			t.Errorf("jittered Delay(%d) = %s, want a positive delay", attempt, d)
+		}
+	}
+}
+
+func TestDecorrelatedJitterDefaultsAndLimits(t *testing.T) {
+	one := func() float64 { return 0.999 }
+	if d := (DecorrelatedJitter{}).Delay(1, 0, func() float64 { return 0 }); d != 100*time.Millisecond {
+		t.Errorf("zero Base: Delay = %s, want the 100ms default", d)
+	}
+	for _, prev := range []time.Duration{math.MaxInt64 / 2, math.MaxInt64} {
+		if d := (DecorrelatedJitter{Base: time.Second}).Delay(1, prev, one); d < time.Second {
+			t.Errorf("uncapped Delay(prev=%d) = %s, want no overflow", prev, d)
+		}
+		if d := (DecorrelatedJitter{Base: time.Second, Max: time.Hour}).Delay(1, prev, one); d != time.Hour {
+			t.Errorf("capped Delay(prev=%d) = %s, want 1h", prev, d)
+		}
+	}
+}
+
+func TestMaxAttemptsExhausted(t *testing.T) {
+	clock := newFakeClock()
+	fn, calls := failing(10, errTemporary)
+	p := Policy{MaxAttempts: 3, Backoff: Constant(time.Second), Clock: clock}
+
+	err := p.Do(context.Background(), fn)
+	var exhausted *ExhaustedError
+	if !errors.As(err, &exhausted) || exhausted.Attempts != 3 {
+		t.Fatalf("Do() = %v, want ExhaustedError after 3 attempts", err)
+	}
+	if !errors.Is(err, errTemporary) {
+		t.Errorf("Do() = %v, want it to wrap the last error", err)
+	}
+	if *calls != 3 || len(clock.sleeps) != 2 {
+		t.Errorf("calls=%d sleeps=%d, want 3 and 2", *calls, len(clock.sleeps))
+	}
+}
+
+func TestMaxElapsedStopsBeforeOverrunning(t *testing.T) {
+	clock := newFakeClock()
+	fn, calls := failing(100, errTemporary)
+	p := Policy{MaxElapsed: 10 * time.Second, Backoff: Exponential{Initial: time.Second}, Clock: clock}
+
+	err := p.Do(context.Background(), fn)
+	var exhausted *ExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("Do() = %v, want ExhaustedError", err)
+	}
+	// 1+2+4 = 7s; waiting another 8s would exceed the 10s budget.
+	if *calls != 4 || exhausted.Elapsed != 7*time.Second {
+		t.Errorf("calls=%d elapsed=%s, want 4 calls and 7s", *calls, exhausted.Elapsed)
+	}
+}
+
+func TestClassifier(t *testing.T) {
+	errFatal := errors.New("bad request")
+
+	t.Run("errors.Is classifier", func(t *testing.T) {
+		fn, calls := failing(1, errFatal)
+		p := Policy{MaxAttempts: 5, Retryable: On(errTemporary), Clock: newFakeClock()}
+		if err := p.Do(context.Background(), fn); !errors.Is(err, errFatal) || *calls != 1 {
+			t.Errorf("Do() = %v after %d calls, want errFatal after 1", err, *calls)
+		}
+
+		fn, calls = failing(2, errTemporary)
+		if err := p.Do(context.Background(), fn); err != nil || *calls != 3 {
+			t.Errorf("Do() = %v after %d calls, want success after 3", err, *calls)
+		}
+	})
+
+	t.Run("permanent", func(t *testing.T) {
+		fn, calls := failing(5, Permanent(errFatal))
+		p := Policy{MaxAttempts: 5, Clock: newFakeClock()}
+		err := p.Do(context.Background(), fn)
+		if err != errFatal || *calls != 1 {
+			t.Errorf("Do() = %v after %d calls, want unwrapped errFatal after 1", err, *calls)
+		}
+	})
+
+	t.Run("wrapped permanent keeps context", func(t *testing.T) {
+		fn, calls := failing(5, fmt.Errorf("fetching config: %w", Permanent(errFatal)))
+		p := Policy{MaxAttempts: 5, Clock: newFakeClock()}
+		err := p.Do(context.Background(), fn)
+		if !errors.Is(err, errFatal) || err.Error() != "fetching config: bad request" || *calls != 1 {
+			t.Errorf("Do() = %v after %d calls, want wrapped errFatal after 1", err, *calls)
+		}
+	})
+}
+
+func TestOnRetryHook(t *testing.T) {
+	var seen []Attempt
+	fn, _ := failing(2, errTemporary)
+	p := Policy{
+		MaxAttempts: 5,
+		Backoff:     Constant(time.Second),
+		Clock:       newFakeClock(),
+		OnRetry:     func(a Attempt) { seen = append(seen, a) },
+	}
+	if err := p.Do(context.Background(), fn); err != nil {
+		t.Fatal(err)
+	}
+	want := []Attempt{
+		{Number: 1, Err: errTemporary, Delay: time.Second, Elapsed: 0},
+		{Number: 2, Err: errTemporary, Delay: time.Second, Elapsed: time.Second},
+	}
+	if !reflect.DeepEqual(seen, want) {
+		t.Errorf("OnRetry saw %+v, want %+v", seen, want)
+	}
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	calls := 0
+	p := Policy{MaxAttempts: 2, AttemptTimeout: 10 * time.Millisecond, Clock: newFakeClock()}
+	err := p.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("attempt context has no deadline")
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Errorf("Do() = %v after %d calls, want success on the second attempt", err, calls)
+	}
+}
+
+func TestContextCancelStopsRetrying(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := Policy{MaxAttempts: 10, Clock: newFakeClock()}
+	calls := 0
+	err := p.Do(ctx, func(context.Context) error {
+		calls++
+		cancel()
+		return errTemporary
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("Do() = %v after %d calls, want context.Canceled after 1", err, calls)
+	}
+}
+
+func TestDoValue(t *testing.T) {
+	calls := 0
+	p := Policy{MaxAttempts: 3, Clock: newFakeClock()}
+	got, err := DoValue(context.Background(), p, func(context.Context) (string, error) {
+		calls++
+		if calls < 2 {
+			return "", errTemporary
+		}
+		return "payload", nil
+	})
+	if err != nil || got != "payload" {
+		t.Errorf("DoValue() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,177 @@
+// Package errs provides an error type with cause wrapping, stack capture,
+// typed metadata with redaction, and JSON serialization so errors can
+// cross a service boundary and be rebuilt on the other side.
+package errs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+)
+
+const maxDepth = 32
+
+// Error is a message with an optional code, cause, metadata and the stack
+// where it was created.
+type Error struct {
+	msg    string
+	code   string
+	cause  error
+	fields []Field
+	pcs    []uintptr
+	frames []Frame // set instead of pcs on errors rebuilt from JSON
+}
+
+// Frame is one resolved stack frame.
+type Frame struct {
+	Function string `json:"func"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxDepth)
+	n := runtime.Callers(skip+2, pcs)
+	return pcs[:n]
+}
+
+// New returns an error with the stack of its caller.
+func New(msg string, fields ...Field) *Error {
+	return &Error{msg: msg, fields: fields, pcs: callers(1)}
+}
+
+// Wrap annotates err with msg and the stack of its caller. It returns nil
+// if err is nil.
+func Wrap(err error, msg string, fields ...Field) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{msg: msg, cause: err, fields: fields, pcs: callers(1)}
+}
+
+// WithCode returns a copy of e with a machine-readable code. Errors with
+// the same non-empty code match each other with errors.Is.
+func (e *Error) WithCode(code string) *Error {
+	c := e.clone()
+	c.code = code
+	return c
+}
+
+// WithCause returns a copy of e that wraps err.
+func (e *Error) WithCause(err error) *Error {
+	c := e.clone()
+	c.cause = err
+	return c
+}
+
+// With returns a copy of e with fields added to its metadata.
+func (e *Error) With(fields ...Field) *Error {
+	c := e.clone()
+	c.fields = append(c.fields, fields...)
+	return c
+}
+
+// clone copies e so the With* methods never modify an error that may be
+// shared, such as a package-level sentinel. The stack is kept.
+func (e *Error) clone() *Error {
+	c := *e
+	c.fields = e.fields[:len(e.fields):len(e.fields)]
+	return &c
+}
+
+func (e *Error) Error() string {
+	if e.cause == nil {
+		return e.msg
+	}
+	if e.msg == "" {
+		return e.cause.Error()
+	}
+	return e.msg + ": " + e.cause.Error()
+}
+
+func (e *Error) Unwrap() error { return e.cause }
+
+// Is matches another *Error with the same code, so a package-level
+// sentinel such as New("not found").WithCode("not_found") matches every
+// error carrying that code, wherever it was created.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.code != "" && e.code == t.code
+}
+
+func (e *Error) Code() string { return e.code }
+
+// Fields returns the metadata as given, without redaction.
+func (e *Error) Fields() []Field {
+	return append([]Field(nil), e.fields...)
+}
+
+// StackTrace resolves the captured program counters into frames.
+func (e *Error) StackTrace() []Frame {
+	if e.frames != nil {
+		return e.frames
+	}
+	var out []Frame
+	frames := runtime.CallersFrames(e.pcs)
+	for {
+		f, more := frames.Next()
+		if f.Function != "" {
+			out = append(out, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+// Format implements fmt.Formatter. %s and %v print the message chain;
+// %+v adds redacted metadata, stack frames and every cause.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			e.writeDetailed(s)
+			return
+		}
+		fallthrough
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+func (e *Error) writeDetailed(w io.Writer) {
+	var err error = e
+	for depth := 0; err != nil; depth++ {
+		if depth > 0 {
+			io.WriteString(w, "\ncaused by: ")
+		}
+		var cur *Error
+		if !errors.As(err, &cur) || error(cur) != err {
+			// A foreign error: print it and keep unwrapping.
+			io.WriteString(w, err.Error())
+			err = errors.Unwrap(err)
+			continue
+		}
+
+		io.WriteString(w, cur.msg)
+		if cur.code != "" {
+			fmt.Fprintf(w, " [%s]", cur.code)
+		}
+		if len(cur.fields) > 0 {
+			parts := make([]string, len(cur.fields))
+			for i, f := range cur.fields {
+				parts[i] = DefaultRedactor.Apply(f).String()
+			}
+			fmt.Fprintf(w, " {%s}", strings.Join(parts, ", "))
+		}
+		for _, f := range cur.StackTrace() {
+			fmt.Fprintf(w, "\n    %s\n        %s:%d", f.Function, f.File, f.Line)
+		}
+		err = cur.cause
+	}
+}
@@ -0,0 +1,127 @@
+package errs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithMethodsDoNotModifyReceiver(t *testing.T) {
+	sentinel := New("not found").WithCode("not_found")
+	cause := errors.New("disk")
+
+	a := sentinel.With(String("a", "1"))
+	b := sentinel.With(String("b", "2")).WithCause(cause)
+	c := sentinel.WithCode("other")
+
+	if len(sentinel.Fields()) != 0 || sentinel.Unwrap() != nil || sentinel.Code() != "not_found" {
+		t.Fatalf("sentinel modified: fields=%v cause=%v code=%q", sentinel.Fields(), sentinel.Unwrap(), sentinel.Code())
+	}
+	if got := a.Fields(); len(got) != 1 || got[0].Key != "a" {
+		t.Errorf("a.Fields() = %v", got)
+	}
+	if got := b.Fields(); len(got) != 1 || got[0].Key != "b" || !errors.Is(b, cause) {
+		t.Errorf("b.Fields() = %v, cause %v", got, b.Unwrap())
+	}
+	if !errors.Is(a, sentinel) || errors.Is(c, sentinel) {
+		t.Errorf("code matching: a=%v c=%v", errors.Is(a, sentinel), errors.Is(c, sentinel))
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	root := New("connection refused", Float("load", 0.75))
+	err := New("request failed",
+		Int("big", math.MaxInt64),
+		Int("negative", -1<<60),
+		Bool("retry", true),
+		Duration("elapsed", 1500*time.Millisecond),
+		String("host", "db-1"),
+	).WithCode("upstream").WithCause(Wrap(root, "dialing"))
+
+	data, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		t.Fatal(marshalErr)
+	}
+	got, decodeErr := Decode(data)
+	if decodeErr != nil {
+		t.Fatal(decodeErr)
+	}
+
+	if got.Error() != err.Error() {
+		t.Errorf("Error() = %q, want %q", got.Error(), err.Error())
+	}
+	if !reflect.DeepEqual(got.Fields(), err.Fields()) {
+		t.Errorf("Fields() = %#v, want %#v", got.Fields(), err.Fields())
+	}
+	if !errors.Is(got, New("").WithCode("upstream")) {
+		t.Error("rebuilt error lost its code")
+	}
+	var rebuiltRoot *Error
+	if !errors.As(got.Unwrap().(*Error).Unwrap(), &rebuiltRoot) || !reflect.DeepEqual(rebuiltRoot.Fields(), root.Fields()) {
+		t.Errorf("root cause fields = %v, want %v", rebuiltRoot, root.Fields())
+	}
+	if len(got.StackTrace()) != len(err.StackTrace()) {
+		t.Errorf("stack has %d frames, want %d", len(got.StackTrace()), len(err.StackTrace()))
+	}
+}
+
+func TestDecodeRejectsMistypedField(t *testing.T) {
+	for _, data := range []string{
+		`{"message":"x","fields":[{"key":"n","kind":"int","value":1.5}]}`,
+		`{"message":"x","fields":[{"key":"n","kind":"int","value":"1"}]}`,
+		`{"message":"x","fields":[{"key":"d","kind":"duration","value":"soon"}]}`,
+		`{"message":"x","fields":[{"key":"k","kind":"nope","value":1}]}`,
+	} {
+		if _, err := Decode([]byte(data)); err == nil {
+			t.Errorf("Decode(%s) succeeded", data)
+		}
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	DefaultRedactor.SetKey("card", KeepLast(4))
+	DefaultRedactor.SetKey("name", KeepLast(2))
+	err := New("payment declined",
+		String("apiKey", "sk-live-123"),
+		String("card", "4242424242424242"),
+		String("name", "山田太郎"),
+		String("plan", "pro"),
+	)
+
+	want := []Field{
+		{"apiKey", KindRedacted, "[REDACTED]"},
+		{"card", KindRedacted, "************4242"},
+		{"name", KindRedacted, "**太郎"},
+		{"plan", KindString, "pro"},
+	}
+	for i, f := range err.Fields() {
+		if got := DefaultRedactor.Apply(f); got != want[i] {
+			t.Errorf("Apply(%v) = %#v, want %#v", f, got, want[i])
+		}
+	}
+
+	data, _ := json.Marshal(err)
+	detailed := fmt.Sprintf("%+v", err)
+	for _, secret := range []string{"sk-live-123", "4242424242424242", "山田"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("JSON contains %q: %s", secret, data)
+		}
+		if strings.Contains(detailed, secret) {
+			t.Errorf("%%+v output contains %q", secret)
+		}
+	}
+	if got := err.Fields()[0].Value; got != "sk-live-123" {
+		t.Errorf("Fields() should be unredacted, got %v", got)
+	}
+}
+
+func TestKeepLastShortValueIsFullyRedacted(t *testing.T) {
+	if got := KeepLast(4)(String("pin", "ñ12")); got.Value != "[REDACTED]" {
+		t.Errorf("KeepLast(4) on 3 runes = %v", got.Value)
+	}
+}
@@ -0,0 +1,112 @@
+package errs
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the type of a metadata value. It is kept so that metadata
+// survives a JSON round trip with its type intact.
+type Kind string
+
+const (
+	KindString   Kind = "string"
+	KindInt      Kind = "int"
+	KindFloat    Kind = "float"
+	KindBool     Kind = "bool"
+	KindDuration Kind = "duration"
+	KindRedacted Kind = "redacted"
+)
+
+// Field is one typed piece of error metadata.
+type Field struct {
+	Key   string
+	Kind  Kind
+	Value interface{}
+}
+
+func String(key, v string) Field                 { return Field{key, KindString, v} }
+func Int(key string, v int64) Field              { return Field{key, KindInt, v} }
+func Float(key string, v float64) Field          { return Field{key, KindFloat, v} }
+func Bool(key string, v bool) Field              { return Field{key, KindBool, v} }
+func Duration(key string, v time.Duration) Field { return Field{key, KindDuration, v} }
+
+func (f Field) String() string {
+	return fmt.Sprintf("%s=%v", f.Key, f.Value)
+}
+
+// Rule rewrites a field's value for display or serialization.
+type Rule func(Field) Field
+
+// Redact replaces the value entirely.
+func Redact(f Field) Field {
+	return Field{Key: f.Key, Kind: KindRedacted, Value: "[REDACTED]"}
+}
+
+// KeepLast shows only the last n characters of a string value. It counts
+// runes, not bytes, so multi-byte characters are never split.
+func KeepLast(n int) Rule {
+	return func(f Field) Field {
+		r := []rune(fmt.Sprint(f.Value))
+		if len(r) <= n {
+			return Redact(f)
+		}
+		return Field{Key: f.Key, Kind: KindRedacted, Value: strings.Repeat("*", len(r)-n) + string(r[len(r)-n:])}
+	}
+}
+
+// Redactor decides how each metadata key is shown outside the process.
+// Exact key rules take precedence over pattern rules.
+type Redactor struct {
+	mu       sync.RWMutex
+	keys     map[string]Rule
+	patterns []patternRule
+}
+
+type patternRule struct {
+	re   *regexp.Regexp
+	rule Rule
+}
+
+// NewRedactor returns a Redactor that hides values whose key looks like a
+// credential.
+func NewRedactor() *Redactor {
+	r := &Redactor{keys: make(map[string]Rule)}
+	r.SetPattern(`(?i)(pass(word)?|secret|token|api[_-]?key|authorization|credential)`, Redact)
+	return r
+}
+
+// SetKey applies rule to fields named key, ignoring case.
+func (r *Redactor) SetKey(key string, rule Rule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[strings.ToLower(key)] = rule
+}
+
+// SetPattern applies rule to fields whose key matches the expression.
+func (r *Redactor) SetPattern(expr string, rule Rule) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.patterns = append(r.patterns, patternRule{regexp.MustCompile(expr), rule})
+}
+
+// Apply returns f as it may be shown.
+func (r *Redactor) Apply(f Field) Field {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if rule, ok := r.keys[strings.ToLower(f.Key)]; ok {
+		return rule(f)
+	}
+	for _, p := range r.patterns {
+		if p.re.MatchString(f.Key) {
+			return p.rule(f)
+		}
+	}
+	return f
+}
+
+// DefaultRedactor is used when formatting and serializing errors.
+var DefaultRedactor = NewRedactor()
@@ -0,0 +1,151 @@
+package errs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type jsonField struct {
+	Key   string      `json:"key"`
+	Kind  Kind        `json:"kind"`
+	Value interface{} `json:"value"`
+}
+
+type jsonError struct {
+	Message string      `json:"message"`
+	Code    string      `json:"code,omitempty"`
+	Fields  []jsonField `json:"fields,omitempty"`
+	Stack   []Frame     `json:"stack,omitempty"`
+	Cause   *jsonError  `json:"cause,omitempty"`
+}
+
+// MarshalJSON serializes the whole cause chain. Metadata passes through
+// DefaultRedactor first, so secrets never leave the process. A cause that
+// is not an *Error is sent as its message alone, ending the chain.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(toJSON(e))
+}
+
+func toJSON(err error) *jsonError {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if !errors.As(err, &e) || error(e) != err {
+		return &jsonError{Message: err.Error()}
+	}
+	j := &jsonError{Message: e.msg, Code: e.code, Stack: e.StackTrace(), Cause: toJSON(e.cause)}
+	for _, f := range e.fields {
+		f = DefaultRedactor.Apply(f)
+		v := f.Value
+		if d, ok := v.(time.Duration); ok {
+			v = d.String()
+		}
+		j.Fields = append(j.Fields, jsonField{Key: f.Key, Kind: f.Kind, Value: v})
+	}
+	return j
+}
+
+// UnmarshalJSON rebuilds an error, its metadata with original types and
+// its causes from the output of MarshalJSON.
+func (e *Error) UnmarshalJSON(data []byte) error {
+	// UseNumber keeps int64 metadata exact; float64 loses precision
+	// above 2^53.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var j jsonError
+	if err := dec.Decode(&j); err != nil {
+		return err
+	}
+	rebuilt, err := fromJSON(&j)
+	if err != nil {
+		return err
+	}
+	*e = *rebuilt
+	return nil
+}
+
+// Decode is a convenience for rebuilding a received error.
+func Decode(data []byte) (*Error, error) {
+	e := new(Error)
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func fromJSON(j *jsonError) (*Error, error) {
+	e := &Error{msg: j.Message, code: j.Code, frames: j.Stack}
+	if e.frames == nil {
+		e.frames = []Frame{}
+	}
+	for _, jf := range j.Fields {
+		f, err := decodeField(jf)
+		if err != nil {
+			return nil, err
+		}
+		e.fields = append(e.fields, f)
+	}
+	if j.Cause != nil {
+		cause, err := fromJSON(j.Cause)
+		if err != nil {
+			return nil, err
+		}
+		e.cause = cause
+	}
+	return e, nil
+}
+
+func decodeField(jf jsonField) (Field, error) {
+	bad := func() (Field, error) {
+		return Field{}, fmt.Errorf("errs: field %q: %v is not a valid %s", jf.Key, jf.Value, jf.Kind)
+	}
+	switch jf.Kind {
+	case KindString, KindRedacted:
+		s, ok := jf.Value.(string)
+		if !ok {
+			return bad()
+		}
+		return Field{jf.Key, jf.Kind, s}, nil
+	case KindInt:
+		num, ok := jf.Value.(json.Number)
+		if !ok {
+			return bad()
+		}
+		n, err := num.Int64()
+		if err != nil {
+			return bad()
+		}
+		return Int(jf.Key, n), nil
+	case KindFloat:
+		num, ok := jf.Value.(json.Number)
+		if !ok {
+			return bad()
+		}
+		n, err := num.Float64()
+		if err != nil {
+			return bad()
+		}
+		return Float(jf.Key, n), nil
+	case KindBool:
+		b, ok := jf.Value.(bool)
+		if !ok {
+			return bad()
+		}
+		return Bool(jf.Key, b), nil
+	case KindDuration:
+		s, ok := jf.Value.(string)
+		if !ok {
+			return bad()
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return bad()
+		}
+		return Duration(jf.Key, d), nil
+	}
+	return Field{}, fmt.Errorf("errs: field %q has unknown kind %q", jf.Key, jf.Kind)
+}
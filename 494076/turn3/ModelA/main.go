@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"turing/494076/turn3/ModelA/errs"
+)
+
+// ErrValidation matches any error created with the "validation" code.
+var ErrValidation = errs.New("validation failed").WithCode("validation")
+
+func loadConfig(path string) error {
+	_, err := os.ReadFile(path)
+	return errs.Wrap(err, "loading config", errs.String("path", path))
+}
+
+func validate(userID int64, apiKey string) error {
+	err := loadConfig("/does/not/exist.yaml")
+	if err == nil {
+		return nil
+	}
+	return errs.New("data validation failed",
+		errs.Int("userId", userID),
+		errs.String("apiKey", apiKey),
+		errs.String("email", "alice@example.com"),
+		errs.Duration("elapsed", 42*time.Millisecond),
+	).WithCode("validation").WithCause(err)
+}
+
+func main() {
+	errs.DefaultRedactor.SetKey("email", errs.KeepLast(11))
+
+	err := validate(123, "sk-live-0123456789")
+
+	fmt.Printf("%v\n\n", err)
+	fmt.Printf("%+v\n\n", err)
+
+	fmt.Println("errors.Is(err, ErrValidation):", errors.Is(err, ErrValidation))
+	fmt.Println("errors.Is(err, fs.ErrNotExist):", errors.Is(err, fs.ErrNotExist))
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		fmt.Println("errors.As found *fs.PathError for", pathErr.Path)
+	}
+
+	// Send the error across a service boundary and rebuild it.
+	payload, marshalErr := json.Marshal(err)
+	if marshalErr != nil {
+		fmt.Println("marshal:", marshalErr)
+		return
+	}
+	fmt.Printf("\nwire: %.300s...\n\n", payload)
+
+	remote, decodeErr := errs.Decode(payload)
+	if decodeErr != nil {
+		fmt.Println("decode:", decodeErr)
+		return
+	}
+	fmt.Println("rebuilt:", remote)
+	fmt.Println("rebuilt errors.Is(ErrValidation):", errors.Is(remote, ErrValidation))
+	for _, f := range remote.Fields() {
+		fmt.Printf("  %s (%s) = %v [%T]\n", f.Key, f.Kind, f.Value, f.Value)
+	}
+}
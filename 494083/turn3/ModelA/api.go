@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// api exposes a PostManager over HTTP.
+type api struct {
+	posts *PostManager
+}
+
+func (a *api) routes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /posts", a.listPosts)
+	mux.HandleFunc("POST /posts", a.createPost)
+	mux.HandleFunc("GET /posts/{id}", a.getPost)
+	mux.HandleFunc("PUT /posts/{id}", a.updatePost)
+	mux.HandleFunc("DELETE /posts/{id}", a.deletePost)
+	mux.HandleFunc("GET /posts/{id}/comments", a.listComments)
+	mux.HandleFunc("POST /posts/{id}/comments", a.addComment)
+	mux.HandleFunc("GET /posts/{id}/comments/{cid}", a.getComment)
+	mux.HandleFunc("PUT /posts/{id}/comments/{cid}", a.updateComment)
+	mux.HandleFunc("DELETE /posts/{id}/comments/{cid}", a.deleteComment)
+}
+
+type postInput struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+type commentInput struct {
+	Text string `json:"text"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrPostNotFound), errors.Is(err, ErrCommentNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrInvalid):
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func pathInt(r *http.Request, name string) (int, error) {
+	n, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		return 0, ErrInvalid
+	}
+	return n, nil
+}
+
+func pageParams(r *http.Request) (int, int) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+	return page, perPage
+}
+
+func decode(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return ErrInvalid
+	}
+	return nil
+}
+
+func (a *api) listPosts(w http.ResponseWriter, r *http.Request) {
+	page, perPage := pageParams(r)
+	writeJSON(w, http.StatusOK, a.posts.ListPosts(page, perPage))
+}
+
+func (a *api) createPost(w http.ResponseWriter, r *http.Request) {
+	var in postInput
+	if err := decode(r, &in); err != nil {
+		writeError(w, err)
+		return
+	}
+	p, err := a.posts.CreatePost(in.Title, in.Content)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (a *api) getPost(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err == nil {
+		var p Post
+		if p, err = a.posts.GetPost(id); err == nil {
+			writeJSON(w, http.StatusOK, p)
+			return
+		}
+	}
+	writeError(w, err)
+}
+
+func (a *api) updatePost(w http.ResponseWriter, r *http.Request) {
+	var in postInput
+	id, err := pathInt(r, "id")
+	if err == nil {
+		err = decode(r, &in)
+	}
+	if err == nil {
+		var p Post
+		if p, err = a.posts.UpdatePost(id, in.Title, in.Content); err == nil {
+			writeJSON(w, http.StatusOK, p)
+			return
+		}
+	}
+	writeError(w, err)
+}
+
+func (a *api) deletePost(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err == nil {
+		if err = a.posts.DeletePost(id); err == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, err)
+}
+
+func (a *api) listComments(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	if err == nil {
+		page, perPage := pageParams(r)
+		var out Page[Comment]
+		if out, err = a.posts.ListComments(id, page, perPage); err == nil {
+			writeJSON(w, http.StatusOK, out)
+			return
+		}
+	}
+	writeError(w, err)
+}
+
+func (a *api) addComment(w http.ResponseWriter, r *http.Request) {
+	var in commentInput
+	id, err := pathInt(r, "id")
+	if err == nil {
+		err = decode(r, &in)
+	}
+	if err == nil {
+		var c Comment
+		if c, err = a.posts.AddComment(id, in.Text); err == nil {
+			writeJSON(w, http.StatusCreated, c)
+			return
+		}
+	}
+	writeError(w, err)
+}
+
+func (a *api) getComment(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	cid, cerr := pathInt(r, "cid")
+	if err == nil && cerr == nil {
+		var c Comment
+		if c, err = a.posts.GetComment(id, cid); err == nil {
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+	}
+	writeError(w, errors.Join(err, cerr))
+}
+
+func (a *api) updateComment(w http.ResponseWriter, r *http.Request) {
+	var in commentInput
+	id, err := pathInt(r, "id")
+	cid, cerr := pathInt(r, "cid")
+	if err == nil && cerr == nil {
+		err = decode(r, &in)
+	}
+	if err == nil && cerr == nil {
+		var c Comment
+		if c, err = a.posts.UpdateComment(id, cid, in.Text); err == nil {
+			writeJSON(w, http.StatusOK, c)
+			return
+		}
+	}
+	writeError(w, errors.Join(err, cerr))
+}
+
+func (a *api) deleteComment(w http.ResponseWriter, r *http.Request) {
+	id, err := pathInt(r, "id")
+	cid, cerr := pathInt(r, "cid")
+	if err == nil && cerr == nil {
+		if err = a.posts.DeleteComment(id, cid); err == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, errors.Join(err, cerr))
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Define a Post struct
+type Post struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"createdAt"`
+	Comments  []Comment `json:"comments,omitempty"`
+}
+
+// Define a Comment struct
+type Comment struct {
+	ID        int       `json:"id"`
+	PostID    int       `json:"postId"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+var (
+	ErrPostNotFound    = errors.New("post not found")
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrInvalid         = errors.New("invalid input")
+)
+
+// Page is one page of a listing.
+type Page[T any] struct {
+	Items   []T `json:"items"`
+	Page    int `json:"page"`
+	PerPage int `json:"perPage"`
+	Total   int `json:"total"`
+}
+
+// PostManager stores posts and comments with O(1) lookup by ID and a
+// per-post comment index, instead of scanning slices.
+type PostManager struct {
+	mu             sync.RWMutex
+	posts          map[int]Post
+	postIDs        []int // ascending, for stable pagination
+	comments       map[int]Comment
+	commentsByPost map[int][]int // comment IDs per post, ascending
+	lastPostID     int
+	lastCommentID  int
+	now            func() time.Time
+}
+
+func NewPostManager() *PostManager {
+	return &PostManager{
+		posts:          make(map[int]Post),
+		comments:       make(map[int]Comment),
+		commentsByPost: make(map[int][]int),
+		now:            time.Now,
+	}
+}
+
+func (m *PostManager) CreatePost(title, content string) (Post, error) {
+	if strings.TrimSpace(title) == "" {
+		return Post{}, ErrInvalid
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastPostID++
+	p := Post{ID: m.lastPostID, Title: title, Content: content, CreatedAt: m.now()}
+	m.posts[p.ID] = p
+	m.postIDs = append(m.postIDs, p.ID)
+	return p, nil
+}
+
+// GetPost returns the post with its comments attached.
+func (m *PostManager) GetPost(id int) (Post, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	p, ok := m.posts[id]
+	if !ok {
+		return Post{}, ErrPostNotFound
+	}
+	ids := m.commentsByPost[id]
+	p.Comments = make([]Comment, len(ids))
+	for i, cid := range ids {
+		p.Comments[i] = m.comments[cid]
+	}
+	return p, nil
+}
+
+func (m *PostManager) UpdatePost(id int, title, content string) (Post, error) {
+	if strings.TrimSpace(title) == "" {
+		return Post{}, ErrInvalid
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p, ok := m.posts[id]
+	if !ok {
+		return Post{}, ErrPostNotFound
+	}
+	p.Title, p.Content = title, content
+	m.posts[id] = p
+	return p, nil
+}
+
+// DeletePost removes a post and all of its comments.
+func (m *PostManager) DeletePost(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.posts[id]; !ok {
+		return ErrPostNotFound
+	}
+	for _, cid := range m.commentsByPost[id] {
+		delete(m.comments, cid)
+	}
+	delete(m.commentsByPost, id)
+	delete(m.posts, id)
+	m.postIDs = removeSorted(m.postIDs, id)
+	return nil
+}
+
+// ListPosts returns one page of posts, oldest first, without comments.
+func (m *PostManager) ListPosts(page, perPage int) Page[Post] {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids, page, perPage := paginate(m.postIDs, page, perPage)
+	out := Page[Post]{Items: make([]Post, len(ids)), Page: page, PerPage: perPage, Total: len(m.postIDs)}
+	for i, id := range ids {
+		out.Items[i] = m.posts[id]
+	}
+	return out
+}
+
+func (m *PostManager) AddComment(postID int, text string) (Comment, error) {
+	if strings.TrimSpace(text) == "" {
+		return Comment{}, ErrInvalid
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.posts[postID]; !ok {
+		return Comment{}, ErrPostNotFound
+	}
+	m.lastCommentID++
+	c := Comment{ID: m.lastCommentID, PostID: postID, Text: text, CreatedAt: m.now()}
+	m.comments[c.ID] = c
+	m.commentsByPost[postID] = append(m.commentsByPost[postID], c.ID)
+	return c, nil
+}
+
+func (m *PostManager) GetComment(postID, id int) (Comment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lookupComment(postID, id)
+}
+
+func (m *PostManager) UpdateComment(postID, id int, text string) (Comment, error) {
+	if strings.TrimSpace(text) == "" {
+		return Comment{}, ErrInvalid
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, err := m.lookupComment(postID, id)
+	if err != nil {
+		return Comment{}, err
+	}
+	c.Text = text
+	m.comments[id] = c
+	return c, nil
+}
+
+func (m *PostManager) DeleteComment(postID, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.lookupComment(postID, id); err != nil {
+		return err
+	}
+	delete(m.comments, id)
+	m.commentsByPost[postID] = removeSorted(m.commentsByPost[postID], id)
+	return nil
+}
+
+func (m *PostManager) ListComments(postID, page, perPage int) (Page[Comment], error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, ok := m.posts[postID]; !ok {
+		return Page[Comment]{}, ErrPostNotFound
+	}
+	all := m.commentsByPost[postID]
+	ids, page, perPage := paginate(all, page, perPage)
+	out := Page[Comment]{Items: make([]Comment, len(ids)), Page: page, PerPage: perPage, Total: len(all)}
+	for i, id := range ids {
+		out.Items[i] = m.comments[id]
+	}
+	return out, nil
+}
+
+// lookupComment finds a comment belonging to postID. The caller holds m.mu.
+func (m *PostManager) lookupComment(postID, id int) (Comment, error) {
+	if _, ok := m.posts[postID]; !ok {
+		return Comment{}, ErrPostNotFound
+	}
+	c, ok := m.comments[id]
+	if !ok || c.PostID != postID {
+		return Comment{}, ErrCommentNotFound
+	}
+	return c, nil
+}
+
+const maxPerPage = 100
+
+// paginate clamps page (1-based) and perPage and returns that page of ids.
+func paginate(ids []int, page, perPage int) ([]int, int, int) {
+	if perPage <= 0 {
+		perPage = 20
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	if page <= 0 {
+		page = 1
+	}
+	start := (page - 1) * perPage
+	if start >= len(ids) {
+		return nil, page, perPage
+	}
+	end := start + perPage
+	if end > len(ids) {
+		end = len(ids)
+	}
+	return ids[start:end], page, perPage
+}
+
+func removeSorted(ids []int, id int) []int {
+	i := sort.SearchInts(ids, id)
+	if i < len(ids) && ids[i] == id {
+		return append(ids[:i], ids[i+1:]...)
+	}
+	return ids
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+const (
+	benchPosts           = 10000
+	benchCommentsPerPost = 5
+)
+
+func seedStores() (*sliceStore, *PostManager) {
+	ss := &sliceStore{}
+	pm := NewPostManager()
+	for i := 1; i <= benchPosts; i++ {
+		p, _ := pm.CreatePost(fmt.Sprintf("Post %d", i), "content")
+		ss.posts = append(ss.posts, p)
+		for j := 0; j < benchCommentsPerPost; j++ {
+			c, _ := pm.AddComment(p.ID, "comment")
+			ss.comments = append(ss.comments, c)
+		}
+	}
+	return ss, pm
+}
+
+func BenchmarkGetPost(b *testing.B) {
+	ss, pm := seedStores()
+
+	b.Run("slice", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, ok := ss.getPost(i%benchPosts + 1); !ok {
+				b.Fatal("missing post")
+			}
+		}
+	})
+	b.Run("indexed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := pm.GetPost(i%benchPosts + 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkCommentsForPost(b *testing.B) {
+	ss, pm := seedStores()
+
+	b.Run("slice", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if len(ss.commentsFor(i%benchPosts+1)) != benchCommentsPerPost {
+				b.Fatal("wrong comment count")
+			}
+		}
+	})
+	b.Run("indexed", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			page, err := pm.ListComments(i%benchPosts+1, 1, 20)
+			if err != nil || len(page.Items) != benchCommentsPerPost {
+				b.Fatal("wrong comment count")
+			}
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"net/http/pprof"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	enablePprof := flag.Bool("pprof", false, "serve runtime profiles under /debug/pprof/")
+	flag.Parse()
+
+	pm := NewPostManager()
+	first, _ := pm.CreatePost("Hello, World!", "This is my first post.")
+	pm.AddComment(first.ID, "Great post!")
+
+	mux := http.NewServeMux()
+	(&api{posts: pm}).routes(mux)
+
+	if *enablePprof {
+		// Registered on our own mux rather than http.DefaultServeMux, so
+		// the profiles are only reachable when the flag is set.
+		mux.HandleFunc("/debug/pprof/", pprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		log.Println("pprof enabled at /debug/pprof/")
+	}
+
+	log.Println("Server running on", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
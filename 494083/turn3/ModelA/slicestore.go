@@ -0,0 +1,37 @@
+package main
+
+// sliceStore is the original slice-based approach, kept as the baseline
+// for the benchmarks: every lookup scans.
+type sliceStore struct {
+	posts    []Post
+	comments []Comment
+}
+
+func (s *sliceStore) findPost(id int) (Post, bool) {
+	for _, p := range s.posts {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Post{}, false
+}
+
+// getPost is findPost plus the post's comments, the same work
+// PostManager.GetPost does.
+func (s *sliceStore) getPost(id int) (Post, bool) {
+	p, ok := s.findPost(id)
+	if ok {
+		p.Comments = s.commentsFor(id)
+	}
+	return p, ok
+}
+
+func (s *sliceStore) commentsFor(postID int) []Comment {
+	var out []Comment
+	for _, c := range s.comments {
+		if c.PostID == postID {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Collector aggregates errors per correlation ID. It is safe for use by
+// many goroutines at once.
+type Collector struct {
+	mu     sync.Mutex
+	seq    uint64
+	byCorr map[string][]recorded
+}
+
+type recorded struct {
+	seq uint64
+	err *CustomError
+}
+
+func NewCollector() *Collector {
+	return &Collector{byCorr: make(map[string][]recorded)}
+}
+
+// Record adds e under its correlation ID.
+func (c *Collector) Record(e *CustomError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	c.byCorr[e.CorrelationID] = append(c.byCorr[e.CorrelationID], recorded{c.seq, e})
+}
+
+// Errors returns the errors recorded for id in timestamp order. Errors with
+// equal timestamps keep the order they were recorded in.
+func (c *Collector) Errors(id string) []*CustomError {
+	c.mu.Lock()
+	recs := append([]recorded(nil), c.byCorr[id]...)
+	c.mu.Unlock()
+
+	sort.Slice(recs, func(i, j int) bool {
+		ti, tj := recs[i].err.Timestamp, recs[j].err.Timestamp
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return recs[i].seq < recs[j].seq
+	})
+	out := make([]*CustomError, len(recs))
+	for i, r := range recs {
+		out[i] = r.err
+	}
+	return out
+}
+
+// CorrelationIDs lists every ID that has at least one error, sorted.
+func (c *Collector) CorrelationIDs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]string, 0, len(c.byCorr))
+	for id := range c.byCorr {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+type errorJSON struct {
+	Timestamp time.Time `json:"timestamp"`
+	Severity  Severity  `json:"severity"`
+	Message   string    `json:"message"`
+	Cause     string    `json:"cause,omitempty"`
+}
+
+type reportJSON struct {
+	CorrelationID string      `json:"correlationId"`
+	Count         int         `json:"count"`
+	Errors        []errorJSON `json:"errors"`
+}
+
+// Report writes every error recorded for id to w as indented JSON, oldest
+// first.
+func (c *Collector) Report(w io.Writer, id string) error {
+	errs := c.Errors(id)
+	rep := reportJSON{CorrelationID: id, Count: len(errs), Errors: make([]errorJSON, len(errs))}
+	for i, e := range errs {
+		rep.Errors[i] = errorJSON{Timestamp: e.Timestamp, Severity: e.Severity, Message: e.Message}
+		if e.Err != nil {
+			rep.Errors[i].Cause = e.Err.Error()
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rep)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Severity ranks how bad an error is.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+var severityNames = [...]string{"INFO", "WARNING", "ERROR", "CRITICAL"}
+
+func (s Severity) String() string {
+	if s < 0 || int(s) >= len(severityNames) {
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+	return severityNames[s]
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(b []byte) error {
+	for i, name := range severityNames {
+		if strings.EqualFold(string(b), name) {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q", b)
+}
+
+// Unexported key types so nothing outside this file can collide with or
+// overwrite these values.
+type (
+	correlationKey struct{}
+	severityKey    struct{}
+	collectorKey   struct{}
+)
+
+// WithCorrelationID returns a context carrying id. Errors wrapped with
+// this context are stamped with it.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationKey{}, id)
+}
+
+// CorrelationID returns the correlation ID in ctx, or "" if none is set.
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// WithSeverity sets the severity used for errors wrapped with ctx.
+func WithSeverity(ctx context.Context, s Severity) context.Context {
+	return context.WithValue(ctx, severityKey{}, s)
+}
+
+// SeverityFrom returns the severity in ctx, defaulting to SeverityError.
+func SeverityFrom(ctx context.Context) Severity {
+	if s, ok := ctx.Value(severityKey{}).(Severity); ok {
+		return s
+	}
+	return SeverityError
+}
+
+// WithCollector makes Wrap record every new error into c.
+func WithCollector(ctx context.Context, c *Collector) context.Context {
+	return context.WithValue(ctx, collectorKey{}, c)
+}
+
+func collectorFrom(ctx context.Context) *Collector {
+	c, _ := ctx.Value(collectorKey{}).(*Collector)
+	return c
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CustomError represents a structured error with additional information
+type CustomError struct {
+	Message       string
+	Severity      Severity
+	CorrelationID string
+	Timestamp     time.Time
+	Err           error
+}
+
+func (e *CustomError) Error() string {
+	var inner *CustomError
+	if errors.As(e.Err, &inner) {
+		// The inner error already shows severity and correlation.
+		return e.Message + ": " + e.Err.Error()
+	}
+	msg := fmt.Sprintf("[%s] %s", e.Severity, e.Message)
+	if e.CorrelationID != "" {
+		msg += " (correlation " + e.CorrelationID + ")"
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *CustomError) Unwrap() error { return e.Err }
+
+// stamp builds a CustomError from the values carried by ctx and records it
+// in ctx's Collector, if any.
+func stamp(ctx context.Context, msg string, err error) *CustomError {
+	e := &CustomError{
+		Message:       msg,
+		Severity:      SeverityFrom(ctx),
+		CorrelationID: CorrelationID(ctx),
+		Timestamp:     time.Now(),
+		Err:           err,
+	}
+	if c := collectorFrom(ctx); c != nil {
+		c.Record(e)
+	}
+	return e
+}
+
+// Wrap annotates err with msg and the correlation ID and severity carried
+// by ctx. The first time an error is wrapped it is also recorded in the
+// ctx's Collector, if any; re-wrapping an already stamped error only adds
+// context, so one failure is reported once. Wrap returns nil for a nil err.
+func Wrap(ctx context.Context, err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	var prev *CustomError
+	if !errors.As(err, &prev) {
+		return stamp(ctx, msg, err)
+	}
+	id := CorrelationID(ctx)
+	if id == "" {
+		id = prev.CorrelationID
+	}
+	return &CustomError{
+		Message:       msg,
+		Severity:      prev.Severity,
+		CorrelationID: id,
+		Timestamp:     time.Now(),
+		Err:           err,
+	}
+}
+
+// Errorf creates a new error stamped and recorded the same way as Wrap.
+func Errorf(ctx context.Context, format string, args ...interface{}) error {
+	return stamp(ctx, fmt.Sprintf(format, args...), nil)
+}
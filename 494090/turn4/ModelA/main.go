@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+var errEmpty = errors.New("data is empty")
+
+func processData(ctx context.Context, data []byte) error {
+	// Simulate processing
+	if len(data) == 0 {
+		return Wrap(ctx, errEmpty, "data processing failed")
+	}
+
+	if string(data) == "invalid" {
+		return Errorf(ctx, "data processing failed: invalid data %q", data)
+	}
+
+	return nil
+}
+
+func handleRequest(ctx context.Context, inputs [][]byte) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(inputs))
+
+	// Every goroutine shares the request's context, so its errors carry
+	// the same correlation ID no matter which goroutine produced them.
+	for i, data := range inputs {
+		wg.Add(1)
+		go func(i int, data []byte) {
+			defer wg.Done()
+			ctx := ctx
+			if i%2 == 1 {
+				ctx = WithSeverity(ctx, SeverityWarning)
+			}
+			if err := processData(ctx, data); err != nil {
+				// Re-wrapping adds context but isn't recorded twice.
+				errs[i] = Wrap(ctx, err, fmt.Sprintf("input %d", i))
+			}
+		}(i, data)
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+func main() {
+	collector := NewCollector()
+	base := WithCollector(context.Background(), collector)
+
+	requests := map[string][][]byte{
+		"ABCD1234": {[]byte("some-data"), []byte(""), []byte("invalid"), []byte("")},
+		"EFGH5678": {[]byte("invalid"), []byte("ok")},
+	}
+	for id, inputs := range requests {
+		ctx := WithCorrelationID(base, id)
+		if err := handleRequest(ctx, inputs); err != nil {
+			fmt.Printf("request %s failed:\n%v\n\n", id, err)
+		}
+	}
+
+	for _, id := range collector.CorrelationIDs() {
+		if err := collector.Report(os.Stdout, id); err != nil {
+			fmt.Fprintln(os.Stderr, "report:", err)
+			os.Exit(1)
+		}
+	}
+}
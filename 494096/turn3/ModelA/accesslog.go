@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogFormat selects how AccessLog writes each line.
+type LogFormat int
+
+const (
+	FormatCommon LogFormat = iota
+	FormatCombined
+	FormatJSON
+)
+
+func ParseLogFormat(s string) (LogFormat, error) {
+	switch strings.ToLower(s) {
+	case "common":
+		return FormatCommon, nil
+	case "combined":
+		return FormatCombined, nil
+	case "json":
+		return FormatJSON, nil
+	}
+	return 0, fmt.Errorf("unknown log format %q", s)
+}
+
+// unmatchedRoute labels requests that never reached a Route-wrapped handler.
+const unmatchedRoute = "unmatched"
+
+// AccessLog is middleware that writes one log line per request and feeds
+// per-route latency into Metrics.
+type AccessLog struct {
+	Out            io.Writer
+	Format         LogFormat
+	TrustedProxies []*net.IPNet
+	Metrics        *Metrics // optional
+
+	mu sync.Mutex // serializes writes to Out
+}
+
+type entry struct {
+	Time      time.Time `json:"time"`
+	ClientIP  string    `json:"client_ip"`
+	User      string    `json:"user,omitempty"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Proto     string    `json:"proto"`
+	Route     string    `json:"route"`
+	Status    int       `json:"status"`
+	Bytes     int64     `json:"bytes"`
+	LatencyMS float64   `json:"latency_ms"`
+	Referer   string    `json:"referer,omitempty"`
+	UserAgent string    `json:"user_agent,omitempty"`
+}
+
+func (a *AccessLog) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := newResponseRecorder(w)
+		rec.route = unmatchedRoute
+
+		defer func() {
+			// A panicking handler never sets an error status itself, but
+			// net/http aborts the response, so log it as a 500 and let
+			// the panic continue up to the server.
+			p := recover()
+			if p != nil {
+				rec.status = http.StatusInternalServerError
+			}
+			latency := time.Since(start)
+			user, _, _ := r.BasicAuth()
+			e := entry{
+				Time:      start,
+				ClientIP:  clientIP(r, a.TrustedProxies),
+				User:      user,
+				Method:    r.Method,
+				Path:      r.URL.RequestURI(),
+				Proto:     r.Proto,
+				Route:     rec.route,
+				Status:    rec.status,
+				Bytes:     rec.bytes,
+				LatencyMS: float64(latency.Microseconds()) / 1000,
+				Referer:   r.Referer(),
+				UserAgent: r.UserAgent(),
+			}
+			if a.Metrics != nil {
+				a.Metrics.Observe(e.Route, latency)
+			}
+			a.write(e)
+			if p != nil {
+				panic(p)
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// Route labels the requests handled by h for metrics and logs. Wrap each
+// handler at registration so the label is the pattern, not the raw path,
+// keeping the number of histograms bounded.
+func Route(name string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rec, ok := w.(*responseRecorder); ok {
+			rec.route = name
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (a *AccessLog) write(e entry) {
+	var line []byte
+	switch a.Format {
+	case FormatJSON:
+		b, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		line = append(b, '\n')
+	default:
+		line = []byte(formatCLF(e, a.Format == FormatCombined))
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.Out.Write(line)
+}
+
+// formatCLF renders e in the NCSA Common Log Format, optionally followed
+// by the Combined fields.
+func formatCLF(e entry, combined bool) string {
+	// The user field is unquoted, so a space would shift every field
+	// after it.
+	user := strings.ReplaceAll(escapeCLF(e.User), " ", `\x20`)
+	if user == "" {
+		user = "-"
+	}
+	size := "-"
+	if e.Bytes > 0 {
+		size = fmt.Sprint(e.Bytes)
+	}
+	line := fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s",
+		e.ClientIP, user, e.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		escapeCLF(e.Method), escapeCLF(e.Path), escapeCLF(e.Proto), e.Status, size)
+	if combined {
+		line += fmt.Sprintf(" %s %s", quoteOrDash(e.Referer), quoteOrDash(e.UserAgent))
+	}
+	return line + "\n"
+}
+
+func quoteOrDash(s string) string {
+	if s == "" {
+		return `"-"`
+	}
+	return `"` + escapeCLF(s) + `"`
+}
+
+// escapeCLF escapes client-supplied text the way Apache does, so a quote
+// or newline cannot end a field early or forge another log line.
+func escapeCLF(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c >= 0x7F:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
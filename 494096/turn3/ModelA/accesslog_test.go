@@ -0,0 +1,30 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCLFEscapesRequestLine(t *testing.T) {
+	var out strings.Builder
+	a := &AccessLog{Out: &out, Format: FormatCombined}
+	h := a.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	r := httptest.NewRequest("GET", "/search", nil)
+	// RequestURI passes the raw query through, so a client can put a
+	// quote and a newline into the logged path.
+	r.URL.RawQuery = "q=\" 200 1\n10.0.0.1 - - [forged] \"GET /"
+	r.Method = `GE"T`
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	line := out.String()
+	if strings.Count(line, "\n") != 1 || !strings.HasSuffix(line, "\n") {
+		t.Fatalf("log output spans more than one line: %q", line)
+	}
+	want := `"GE\"T /search?q=\" 200 1\x0a10.0.0.1 - - [forged] \"GET / HTTP/1.1" 200 -`
+	if !strings.Contains(line, want) {
+		t.Errorf("line = %q, want it to contain %q", line, want)
+	}
+}
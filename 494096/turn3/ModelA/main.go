@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+func hello(w http.ResponseWriter, r *http.Request) {
+	// Parse the "delay" query parameter
+	delayStr := r.URL.Query().Get("delay")
+	delay := 0 // Default delay
+	if delayStr != "" {
+		parsedDelay, err := strconv.Atoi(delayStr)
+		if err != nil || parsedDelay < 0 {
+			http.Error(w, "invalid delay", http.StatusBadRequest)
+			return
+		}
+		delay = parsedDelay
+	}
+
+	// Simulate some work
+	time.Sleep(time.Duration(delay) * time.Second)
+
+	w.Header().Set("Content-Type", "text/plain")
+	fmt.Fprintln(w, "Hello, World!")
+}
+
+// stream writes a line a second, flushing through the logging middleware.
+func stream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	for i := 1; i <= 3; i++ {
+		fmt.Fprintf(w, "tick %d\n", i)
+		flusher.Flush()
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	format := flag.String("format", "combined", "access log format: common, combined or json")
+	proxies := flag.String("trusted-proxies", "127.0.0.1,::1", "comma separated CIDRs whose X-Forwarded-For is trusted")
+	flag.Parse()
+
+	logFormat, err := ParseLogFormat(*format)
+	if err != nil {
+		log.Fatal(err)
+	}
+	trustedProxies, err := parseCIDRs(*proxies)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	metrics := NewMetrics(nil)
+	accessLog := &AccessLog{
+		Out:            os.Stdout,
+		Format:         logFormat,
+		TrustedProxies: trustedProxies,
+		Metrics:        metrics,
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /{$}", Route("/", http.HandlerFunc(hello)))
+	mux.Handle("GET /stream", Route("/stream", http.HandlerFunc(stream)))
+	mux.Handle("GET /metrics", Route("/metrics", metrics))
+
+	log.Println("Server starting on", *addr)
+	if err := http.ListenAndServe(*addr, accessLog.Middleware(mux)); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultBuckets are upper bounds in seconds.
+var defaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type histogram struct {
+	counts []uint64 // per bucket, non-cumulative; last is +Inf
+	sum    float64
+	count  uint64
+}
+
+// Metrics keeps a latency histogram per route.
+type Metrics struct {
+	buckets []float64
+
+	mu     sync.Mutex
+	routes map[string]*histogram
+}
+
+func NewMetrics(buckets []float64) *Metrics {
+	if len(buckets) == 0 {
+		buckets = defaultBuckets
+	}
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	return &Metrics{buckets: b, routes: make(map[string]*histogram)}
+}
+
+func (m *Metrics) Observe(route string, d time.Duration) {
+	v := d.Seconds()
+	i := sort.SearchFloat64s(m.buckets, v)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h, ok := m.routes[route]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets)+1)}
+		m.routes[route] = h
+	}
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// WriteTo writes the histograms in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	routes := make([]string, 0, len(m.routes))
+	snap := make(map[string]histogram, len(m.routes))
+	for r, h := range m.routes {
+		routes = append(routes, r)
+		snap[r] = histogram{counts: append([]uint64(nil), h.counts...), sum: h.sum, count: h.count}
+	}
+	m.mu.Unlock()
+	sort.Strings(routes)
+
+	cw := &countingWriter{w: w}
+	fmt.Fprintln(cw, "# HELP http_request_duration_seconds Request latency by route.")
+	fmt.Fprintln(cw, "# TYPE http_request_duration_seconds histogram")
+	for _, r := range routes {
+		h := snap[r]
+		var cum uint64
+		for i, le := range m.buckets {
+			cum += h.counts[i]
+			fmt.Fprintf(cw, "http_request_duration_seconds_bucket{route=%q,le=%q} %d\n",
+				r, strconv.FormatFloat(le, 'g', -1, 64), cum)
+		}
+		fmt.Fprintf(cw, "http_request_duration_seconds_bucket{route=%q,le=\"+Inf\"} %d\n", r, h.count)
+		fmt.Fprintf(cw, "http_request_duration_seconds_sum{route=%q} %g\n", r, h.sum)
+		fmt.Fprintf(cw, "http_request_duration_seconds_count{route=%q} %d\n", r, h.count)
+	}
+	return cw.n, cw.err
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.WriteTo(w)
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
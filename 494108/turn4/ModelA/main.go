@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	maxWorkers  = 5
+	rateLimit   = 2 // tasks per second
+	burst       = 3
+	taskTimeout = 1500 * time.Millisecond
+)
+
+// processTask simulates work that takes n*300ms and gives up when ctx is
+// done.
+func processTask(n int) Task[int] {
+	return func(ctx context.Context) (int, error) {
+		select {
+		case <-time.After(time.Duration(n) * 300 * time.Millisecond):
+		case <-ctx.Done():
+			return 0, fmt.Errorf("task %d: %w", n, ctx.Err())
+		}
+		if n%7 == 0 {
+			return 0, fmt.Errorf("task %d: unlucky number", n)
+		}
+		return n * n, nil
+	}
+}
+
+func main() {
+	pool := NewPool[int](Config{
+		Workers:     maxWorkers,
+		RateLimit:   rateLimit,
+		Burst:       burst,
+		TaskTimeout: taskTimeout,
+		QueueSize:   10,
+	})
+
+	start := time.Now()
+	futures := make(map[int]*Future[int])
+	for i := 1; i <= 10; i++ {
+		f, err := pool.Submit(context.Background(), processTask(i))
+		if err != nil {
+			fmt.Printf("submit %d: %v\n", i, err)
+			continue
+		}
+		futures[i] = f
+	}
+
+	for i := 1; i <= 10; i++ {
+		f, ok := futures[i]
+		if !ok {
+			continue
+		}
+		v, err := f.Wait(context.Background())
+		elapsed := time.Since(start).Round(100 * time.Millisecond)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			fmt.Printf("[%v] task %d timed out\n", elapsed, i)
+		case err != nil:
+			fmt.Printf("[%v] task %d failed: %v\n", elapsed, i, err)
+		default:
+			fmt.Printf("[%v] task %d = %d\n", elapsed, i, v)
+		}
+	}
+
+	// These are still queued or running when the shutdown deadline hits.
+	late := make([]*Future[int], 0, 5)
+	for i := 11; i <= 15; i++ {
+		if f, err := pool.Submit(context.Background(), processTask(i)); err == nil {
+			late = append(late, f)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := pool.Shutdown(ctx); err != nil {
+		fmt.Println("shutdown:", err)
+	}
+	for _, f := range late {
+		_, err := f.Wait(context.Background())
+		fmt.Println("late task:", err)
+	}
+
+	if _, err := pool.Submit(context.Background(), processTask(1)); err != nil {
+		fmt.Println("submit after shutdown:", err)
+	}
+
+	completed, failed := pool.Stats()
+	fmt.Printf("completed=%d failed=%d\n", completed, failed)
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrPoolClosed = errors.New("worker pool is shut down")
+
+// Task is a unit of work. It must return promptly once ctx is done.
+type Task[T any] func(ctx context.Context) (T, error)
+
+// Result is the outcome of one submitted Task.
+type Result[T any] struct {
+	Value    T
+	Err      error
+	Duration time.Duration
+}
+
+// Future is the pending Result of one submission.
+type Future[T any] struct {
+	done chan struct{}
+	res  Result[T]
+}
+
+// Done is closed once the Result is available, for selecting on several
+// futures at once.
+func (f *Future[T]) Done() <-chan struct{} { return f.done }
+
+// Result returns the outcome. It must only be called after Done is closed.
+func (f *Future[T]) Result() Result[T] { return f.res }
+
+// Wait blocks until the task finishes or ctx is done. It is safe to call
+// from several goroutines.
+func (f *Future[T]) Wait(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.res.Value, f.res.Err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+type job[T any] struct {
+	task   Task[T]
+	future *Future[T]
+}
+
+type Config struct {
+	Workers     int
+	RateLimit   float64 // tasks per second; 0 means unlimited
+	Burst       int
+	TaskTimeout time.Duration
+	QueueSize   int
+}
+
+// Pool runs submitted tasks on a fixed number of workers, paced by a
+// token bucket.
+type Pool[T any] struct {
+	cfg     Config
+	limiter *tokenBucket
+	jobs    chan job[T]
+	wg      sync.WaitGroup
+
+	// ctx is canceled only when Shutdown gives up waiting, aborting
+	// whatever is still running or queued.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// closing is closed as soon as Shutdown starts, waking any Submit
+	// blocked on a full queue so Shutdown can take mu and close jobs.
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	mu     sync.RWMutex
+	closed bool
+
+	completedTasks atomic.Uint64
+	failedTasks    atomic.Uint64
+}
+
+func NewPool[T any](cfg Config) *Pool[T] {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool[T]{
+		cfg:     cfg,
+		jobs:    make(chan job[T], cfg.QueueSize),
+		closing: make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	if cfg.RateLimit > 0 {
+		p.limiter = newTokenBucket(cfg.RateLimit, cfg.Burst)
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		p.wg.Add(1)
+		go p.worker()
+	}
+	return p
+}
+
+// Submit queues task and returns its Future. It blocks while the queue is
+// full, until ctx is done or Shutdown starts.
+func (p *Pool[T]) Submit(ctx context.Context, task Task[T]) (*Future[T], error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	select {
+	case <-p.closing:
+		return nil, ErrPoolClosed
+	default:
+	}
+	f := &Future[T]{done: make(chan struct{})}
+	select {
+	case p.jobs <- job[T]{task: task, future: f}:
+		return f, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-p.closing:
+		return nil, ErrPoolClosed
+	}
+}
+
+func (p *Pool[T]) worker() {
+	defer p.wg.Done()
+	for j := range p.jobs {
+		res := p.run(j.task)
+		if res.Err != nil {
+			p.failedTasks.Add(1)
+		} else {
+			p.completedTasks.Add(1)
+		}
+		j.future.res = res
+		close(j.future.done)
+	}
+}
+
+func (p *Pool[T]) run(task Task[T]) (res Result[T]) {
+	if p.limiter != nil {
+		if err := p.limiter.Wait(p.ctx); err != nil {
+			res.Err = err
+			return res
+		}
+	}
+
+	ctx := p.ctx
+	if p.cfg.TaskTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.cfg.TaskTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	defer func() {
+		res.Duration = time.Since(start)
+		if r := recover(); r != nil {
+			res.Err = &PanicError{Value: r}
+		}
+	}()
+	res.Value, res.Err = task(ctx)
+	return res
+}
+
+// Stats reports how many tasks have finished, by outcome.
+func (p *Pool[T]) Stats() (completed, failed uint64) {
+	return p.completedTasks.Load(), p.failedTasks.Load()
+}
+
+// Shutdown stops accepting work and waits for queued and running tasks to
+// finish. If ctx is done first it cancels them, waits for the workers to
+// exit, and returns ctx.Err(). Every Future still gets a Result.
+func (p *Pool[T]) Shutdown(ctx context.Context) error {
+	p.closeOnce.Do(func() { close(p.closing) })
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// PanicError is the Result error of a task that panicked.
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Mode is the kind of lock held.
+type Mode int
+
+const (
+	Shared Mode = iota
+	Exclusive
+)
+
+func (m Mode) String() string {
+	if m == Exclusive {
+		return "exclusive"
+	}
+	return "shared"
+}
+
+var (
+	ErrNotHeld      = errors.New("lock not held")
+	ErrAlreadyHeld  = errors.New("lock already held by this FileLock")
+	ErrLeaseExpired = errors.New("lock lease expired and was reclaimed")
+)
+
+const (
+	pollMin = 5 * time.Millisecond
+	pollMax = 200 * time.Millisecond
+)
+
+// holder is one entry in the lock file.
+type holder struct {
+	PID     int       `json:"pid"`
+	Token   string    `json:"token"`
+	Mode    Mode      `json:"mode"`
+	Expires time.Time `json:"expires"`
+}
+
+type lockState struct {
+	Holders []holder `json:"holders"`
+}
+
+// FileLock is an advisory lock on a file shared between processes. The
+// lock file "<name>.lock" records every holder's PID and lease expiry;
+// changes to it are serialized by a guard (flock where available, an
+// O_EXCL-created file elsewhere). A holder whose process has died or whose
+// lease has run out is dropped the next time anyone inspects the lock, so
+// a crashed process can't wedge it forever.
+//
+// A FileLock is safe for use by multiple goroutines but holds at most one
+// lock at a time.
+type FileLock struct {
+	fileName  string
+	lockPath  string
+	guardPath string
+	lease     time.Duration
+
+	mu    sync.Mutex
+	token string
+	mode  Mode
+}
+
+// NewFileLock creates a lock for fileName. Holders must call Refresh more
+// often than lease to keep the lock.
+func NewFileLock(fileName string, lease time.Duration) *FileLock {
+	lockPath := fileName + ".lock"
+	return &FileLock{
+		fileName:  fileName,
+		lockPath:  lockPath,
+		guardPath: lockPath + ".guard",
+		lease:     lease,
+	}
+}
+
+// Acquire blocks until the lock is taken in mode or ctx is done.
+func (fl *FileLock) Acquire(ctx context.Context, mode Mode) error {
+	delay := pollMin
+	for {
+		ok, err := fl.TryAcquire(mode)
+		if err != nil || ok {
+			return err
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		if delay *= 2; delay > pollMax {
+			delay = pollMax
+		}
+	}
+}
+
+// TryAcquire takes the lock in mode if that is possible right now.
+func (fl *FileLock) TryAcquire(mode Mode) (bool, error) {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	if fl.token != "" {
+		return false, ErrAlreadyHeld
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return false, err
+	}
+	acquired := false
+	err = fl.update(func(st *lockState) bool {
+		for _, h := range st.Holders {
+			if mode == Exclusive || h.Mode == Exclusive {
+				return false
+			}
+		}
+		st.Holders = append(st.Holders, holder{
+			PID:     os.Getpid(),
+			Token:   token,
+			Mode:    mode,
+			Expires: time.Now().Add(fl.lease),
+		})
+		acquired = true
+		return true
+	})
+	if err != nil || !acquired {
+		return false, err
+	}
+	fl.token, fl.mode = token, mode
+	return true, nil
+}
+
+// Refresh extends the lease. It returns ErrLeaseExpired if the lock was
+// reclaimed by someone else in the meantime.
+func (fl *FileLock) Refresh() error {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	if fl.token == "" {
+		return ErrNotHeld
+	}
+	found := false
+	err := fl.update(func(st *lockState) bool {
+		for i := range st.Holders {
+			if st.Holders[i].Token == fl.token {
+				st.Holders[i].Expires = time.Now().Add(fl.lease)
+				found = true
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return err
+	}
+	if !found {
+		fl.token = ""
+		return ErrLeaseExpired
+	}
+	return nil
+}
+
+// Release gives up the lock.
+func (fl *FileLock) Release() error {
+	fl.mu.Lock()
+	defer fl.mu.Unlock()
+	if fl.token == "" {
+		return ErrNotHeld
+	}
+	found := false
+	err := fl.update(func(st *lockState) bool {
+		for i, h := range st.Holders {
+			if h.Token == fl.token {
+				st.Holders = append(st.Holders[:i], st.Holders[i+1:]...)
+				found = true
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		// Keep the token so the caller can retry; the lease still stands.
+		return err
+	}
+	fl.token = ""
+	if !found {
+		return ErrLeaseExpired
+	}
+	return nil
+}
+
+// IsLocked reports whether anyone currently holds the lock, and in which
+// mode.
+func (fl *FileLock) IsLocked() (bool, Mode, error) {
+	var locked bool
+	var mode Mode
+	err := fl.update(func(st *lockState) bool {
+		for _, h := range st.Holders {
+			locked = true
+			if h.Mode == Exclusive {
+				mode = Exclusive
+			}
+		}
+		return false
+	})
+	return locked, mode, err
+}
+
+// update runs fn on the lock state under the guard, after dropping stale
+// holders. The state is written back if fn returns true or if anything
+// stale was dropped.
+func (fl *FileLock) update(fn func(*lockState) bool) error {
+	release, err := acquireGuard(fl.guardPath)
+	if err != nil {
+		return fmt.Errorf("lock %s: %w", fl.fileName, err)
+	}
+	defer release()
+
+	st, err := readState(fl.lockPath)
+	if err != nil {
+		return fmt.Errorf("lock %s: %w", fl.fileName, err)
+	}
+	reaped := reapStale(st, time.Now())
+	if !fn(st) && !reaped {
+		return nil
+	}
+	if err := writeState(fl.lockPath, st); err != nil {
+		return fmt.Errorf("lock %s: %w", fl.fileName, err)
+	}
+	return nil
+}
+
+// reapStale drops holders whose lease has expired or whose process no
+// longer exists, and reports whether it dropped any.
+func reapStale(st *lockState, now time.Time) bool {
+	live := st.Holders[:0]
+	for _, h := range st.Holders {
+		if now.Before(h.Expires) && processAlive(h.PID) {
+			live = append(live, h)
+		}
+	}
+	reaped := len(live) != len(st.Holders)
+	st.Holders = live
+	return reaped
+}
+
+func readState(path string) (*lockState, error) {
+	st := &lockState{}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return st, nil
+	}
+	if err := json.Unmarshal(b, st); err != nil {
+		return nil, fmt.Errorf("corrupt lock file %s: %w", path, err)
+	}
+	return st, nil
+}
+
+// writeState replaces the lock file atomically so a crash mid-write can't
+// leave it half written. An empty state removes the file.
+func writeState(path string, st *lockState) error {
+	if len(st.Holders) == 0 {
+		err := os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	b, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func newToken() (string, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
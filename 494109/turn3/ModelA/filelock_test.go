@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The test binary doubles as the contending process: when FILELOCK_HELPER
+// is set, TestMain runs the helper instead of the tests.
+func TestMain(m *testing.M) {
+	if mode := os.Getenv("FILELOCK_HELPER"); mode != "" {
+		if err := runHelper(mode, os.Getenv("FILELOCK_PATH")); err != nil {
+			fmt.Fprintln(os.Stderr, "helper:", err)
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func runHelper(mode, path string) error {
+	lock := NewFileLock(path, 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	switch mode {
+	case "increment":
+		// Non-atomic read-modify-write: only correct if the lock excludes
+		// every other process.
+		for i := 0; i < 20; i++ {
+			if err := lock.Acquire(ctx, Exclusive); err != nil {
+				return err
+			}
+			n := readCounter(path)
+			time.Sleep(time.Millisecond)
+			if err := os.WriteFile(path, []byte(strconv.Itoa(n+1)), 0644); err != nil {
+				return err
+			}
+			if err := lock.Release(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "shared":
+		if err := lock.Acquire(ctx, Shared); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path+".readers", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(f, os.Getpid())
+		f.Close()
+		// Stay holding until the test says every reader got in.
+		for {
+			if _, err := os.Stat(path + ".go"); err == nil {
+				return lock.Release()
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	case "crash":
+		if err := lock.Acquire(ctx, Exclusive); err != nil {
+			return err
+		}
+		os.Exit(3) // exit holding the lock
+	}
+	return fmt.Errorf("unknown helper mode %q", mode)
+}
+
+func readCounter(path string) int {
+	b, _ := os.ReadFile(path)
+	n, _ := strconv.Atoi(strings.TrimSpace(string(b)))
+	return n
+}
+
+func helper(t *testing.T, mode, path string) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), "FILELOCK_HELPER="+mode, "FILELOCK_PATH="+path)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestExclusiveAcrossProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "counter")
+	const procs = 5
+
+	cmds := make([]*exec.Cmd, procs)
+	for i := range cmds {
+		cmds[i] = helper(t, "increment", path)
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("helper: %v", err)
+		}
+	}
+	if got, want := readCounter(path), procs*20; got != want {
+		t.Fatalf("counter = %d, want %d (lost updates)", got, want)
+	}
+	if _, err := os.Stat(path + ".lock"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("lock file left behind: %v", err)
+	}
+}
+
+func TestSharedAcrossProcesses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	const procs = 3
+
+	cmds := make([]*exec.Cmd, procs)
+	for i := range cmds {
+		cmds[i] = helper(t, "shared", path)
+	}
+
+	// All readers must hold the lock at the same time.
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		b, _ := os.ReadFile(path + ".readers")
+		if strings.Count(string(b), "\n") == procs {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("only %q readers got the shared lock", b)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// A writer is kept out while they do.
+	writer := NewFileLock(path, time.Second)
+	if ok, err := writer.TryAcquire(Exclusive); err != nil || ok {
+		t.Fatalf("TryAcquire(Exclusive) with readers = %v, %v; want false, nil", ok, err)
+	}
+
+	os.WriteFile(path+".go", nil, 0644)
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("helper: %v", err)
+		}
+	}
+	if ok, err := writer.TryAcquire(Exclusive); err != nil || !ok {
+		t.Fatalf("TryAcquire(Exclusive) after readers = %v, %v; want true, nil", ok, err)
+	}
+	writer.Release()
+}
+
+func TestReclaimFromCrashedProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	cmd := helper(t, "crash", path)
+	var exitErr *exec.ExitError
+	if err := cmd.Wait(); !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("crash helper: %v", err)
+	}
+
+	lock := NewFileLock(path, time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := lock.Acquire(ctx, Exclusive); err != nil {
+		t.Fatalf("Acquire after crash: %v", err)
+	}
+	lock.Release()
+}
+
+func TestReclaimExpiredLease(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	first := NewFileLock(path, 50*time.Millisecond)
+	if ok, err := first.TryAcquire(Exclusive); !ok || err != nil {
+		t.Fatalf("TryAcquire = %v, %v", ok, err)
+	}
+
+	second := NewFileLock(path, time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := second.Acquire(ctx, Exclusive); err != nil {
+		t.Fatalf("Acquire after lease expiry: %v", err)
+	}
+	if err := first.Refresh(); !errors.Is(err, ErrLeaseExpired) {
+		t.Errorf("Refresh on reclaimed lock = %v, want ErrLeaseExpired", err)
+	}
+	second.Release()
+}
+
+func TestAcquireHonorsContext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data")
+	holder := NewFileLock(path, time.Minute)
+	if ok, err := holder.TryAcquire(Shared); !ok || err != nil {
+		t.Fatalf("TryAcquire = %v, %v", ok, err)
+	}
+	defer holder.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := NewFileLock(path, time.Minute).Acquire(ctx, Exclusive)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Acquire = %v, want DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Acquire returned after %v, long after the deadline", d)
+	}
+}
@@ -0,0 +1,57 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// guardStale is how old a guard file must be before it's assumed to have
+// been left behind by a crashed process.
+const guardStale = 10 * time.Second
+
+// acquireGuard creates path with O_EXCL, retrying until it succeeds. On
+// platforms without flock this is the only atomic primitive available.
+func acquireGuard(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) > guardStale {
+			reclaimGuard(path)
+			continue
+		}
+		time.Sleep(pollMin)
+	}
+}
+
+// reclaimGuard removes a stale guard. Stat-then-Remove would race: another
+// waiter may reclaim the same file and create a fresh guard in between,
+// which Remove would then delete. Rename is atomic, so the file is first
+// moved to a name only this process uses and checked again; if it turns
+// out to be a fresh guard it is put back.
+func reclaimGuard(path string) {
+	tmp := fmt.Sprintf("%s.stale.%d.%d", path, os.Getpid(), time.Now().UnixNano())
+	if os.Rename(path, tmp) != nil {
+		return
+	}
+	if fi, err := os.Stat(tmp); err == nil && time.Since(fi.ModTime()) <= guardStale {
+		// Link rather than Rename back, so a guard created since is
+		// never overwritten.
+		os.Link(tmp, path)
+	}
+	os.Remove(tmp)
+}
+
+// processAlive can't be checked portably here; leases alone decide.
+func processAlive(pid int) bool {
+	return true
+}
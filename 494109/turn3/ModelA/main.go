@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+func main() {
+	fileName := flag.String("file", "example.txt", "file to lock")
+	workers := flag.Int("workers", 10, "concurrent workers")
+	lease := flag.Duration("lease", 2*time.Second, "lock lease")
+	flag.Parse()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			// Every third worker only reads, so several can hold the lock
+			// together.
+			mode := Exclusive
+			if i%3 == 0 {
+				mode = Shared
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			lock := NewFileLock(*fileName, *lease)
+			if err := lock.Acquire(ctx, mode); err != nil {
+				log.Printf("worker %d: acquire %s lock: %v", i, mode, err)
+				return
+			}
+			log.Printf("worker %d: %s lock acquired (pid %d)", i, mode, os.Getpid())
+
+			time.Sleep(200 * time.Millisecond) // Simulate some work
+
+			if err := lock.Release(); err != nil {
+				log.Printf("worker %d: release: %v", i, err)
+				return
+			}
+			log.Printf("worker %d: lock released", i)
+		}(i)
+	}
+
+	wg.Wait()
+	log.Printf("done")
+}
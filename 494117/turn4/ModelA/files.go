@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile replaces path with data as a step of tx. The new content is
+// written to a temp file and renamed into place, so readers never see a
+// partial file. The previous content, if any, is kept in a backup until
+// the transaction commits; undo renames it back.
+func WriteFile(tx *Transaction, path string, data []byte, perm os.FileMode) error {
+	var backup string
+	return tx.Do("write "+path,
+		func() error {
+			var err error
+			if backup, err = backupFile(path); err != nil {
+				return err
+			}
+			if err := atomicWrite(path, data, perm); err != nil {
+				removeIfSet(backup)
+				return err
+			}
+			tx.OnCommit(func() error { return removeIfSet(backup) })
+			return nil
+		},
+		func() error { return restore(path, backup) },
+	)
+}
+
+// RemoveFile deletes path as a step of tx. The file is moved aside rather
+// than deleted until the transaction commits.
+func RemoveFile(tx *Transaction, path string) error {
+	var backup string
+	return tx.Do("remove "+path,
+		func() error {
+			f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".bak*")
+			if err != nil {
+				return err
+			}
+			backup = f.Name()
+			f.Close()
+			if err := os.Rename(path, backup); err != nil {
+				os.Remove(backup)
+				return err
+			}
+			tx.OnCommit(func() error { return os.Remove(backup) })
+			return nil
+		},
+		func() error { return os.Rename(backup, path) },
+	)
+}
+
+// backupFile copies path to a sibling temp file and returns its name, or ""
+// if path does not exist.
+func backupFile(path string) (string, error) {
+	src, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	fi, err := src.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	dst, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".bak*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", err
+	}
+	if err := dst.Chmod(fi.Mode().Perm()); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
+}
+
+func atomicWrite(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	cleanup := func(err error) error {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		return cleanup(err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// restore puts backup back at path, or removes path if there was nothing
+// there before.
+func restore(path, backup string) error {
+	if backup == "" {
+		err := os.Remove(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return os.Rename(backup, path)
+}
+
+func removeIfSet(path string) error {
+	if path == "" {
+		return nil
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// manipulateFiles rewrites config.txt, creates data.txt and removes
+// old.txt, then fails if fail is set, which should leave dir untouched.
+func manipulateFiles(dir string, fail bool) (Report, error) {
+	return Run(func(tx *Transaction) error {
+		if err := WriteFile(tx, filepath.Join(dir, "config.txt"), []byte("version=2\n"), 0644); err != nil {
+			return err
+		}
+		if err := WriteFile(tx, filepath.Join(dir, "data.txt"), []byte("Initial data\n"), 0644); err != nil {
+			return err
+		}
+		if err := RemoveFile(tx, filepath.Join(dir, "old.txt")); err != nil {
+			return err
+		}
+		if fail {
+			return errors.New("simulated failure after file changes")
+		}
+		return nil
+	})
+}
+
+// processQueue pops every item and counts it, panicking on a multiple of
+// three.
+func processQueue(q *Queue, c *Counter) (Report, error) {
+	return Run(func(tx *Transaction) error {
+		for {
+			item, ok, err := q.Pop(tx)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+			fmt.Println("Processed item:", item)
+			if item%3 == 0 {
+				panic(fmt.Sprintf("Error processing item %d", item))
+			}
+			if err := c.Increment(tx); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+func listDir(dir string) {
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		b, _ := os.ReadFile(filepath.Join(dir, e.Name()))
+		fmt.Printf("  %s: %q\n", e.Name(), b)
+	}
+}
+
+func printResult(rep Report, err error) {
+	if err != nil {
+		fmt.Println("Transaction failed:", err)
+		for _, u := range rep.Rollback {
+			if u.Err != nil {
+				fmt.Printf("  could not roll back %s: %v\n", u.Step, u.Err)
+			} else {
+				fmt.Println("  rolled back:", u.Step)
+			}
+		}
+		return
+	}
+	fmt.Println("Transaction committed:", rep.Committed)
+	if rep.CleanupErr != nil {
+		fmt.Println("  cleanup failed:", rep.CleanupErr)
+	}
+}
+
+func main() {
+	dir, err := os.MkdirTemp("", "tx-example")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	os.WriteFile(filepath.Join(dir, "config.txt"), []byte("version=1\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "old.txt"), []byte("stale\n"), 0644)
+
+	fmt.Println("Files before:")
+	listDir(dir)
+	printResult(manipulateFiles(dir, true))
+	fmt.Println("Files after failed transaction:")
+	listDir(dir)
+	printResult(manipulateFiles(dir, false))
+	fmt.Println("Files after committed transaction:")
+	listDir(dir)
+
+	fmt.Println()
+	q := &Queue{items: []int{1, 2, 3, 4, 5}}
+	c := &Counter{max: 5}
+	printResult(processQueue(q, c))
+	fmt.Println("Queue:", q.Items(), "Counter:", c.Value())
+
+	q = &Queue{items: []int{1, 2, 4, 5}}
+	c = &Counter{value: 2, max: 5}
+	printResult(processQueue(q, c))
+	fmt.Println("Queue:", q.Items(), "Counter:", c.Value())
+}
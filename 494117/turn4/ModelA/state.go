@@ -0,0 +1,75 @@
+package main
+
+import "fmt"
+
+// Our stateful application represents a simple queue
+type Queue struct {
+	items []int
+}
+
+// Push an element onto the queue
+func (q *Queue) Push(tx *Transaction, item int) error {
+	return tx.Do(fmt.Sprintf("push %d", item),
+		func() error {
+			q.items = append(q.items, item)
+			return nil
+		},
+		func() error {
+			if len(q.items) == 0 || q.items[len(q.items)-1] != item {
+				return fmt.Errorf("queue tail changed, cannot undo push of %d", item)
+			}
+			q.items = q.items[:len(q.items)-1]
+			return nil
+		},
+	)
+}
+
+// Pop an element from the queue. Undoing the step puts it back at the front.
+func (q *Queue) Pop(tx *Transaction) (int, bool, error) {
+	var item int
+	if len(q.items) == 0 {
+		return 0, false, nil
+	}
+	err := tx.Do(fmt.Sprintf("pop %d", q.items[0]),
+		func() error {
+			item = q.items[0]
+			q.items = q.items[1:]
+			return nil
+		},
+		func() error {
+			q.items = append([]int{item}, q.items...)
+			return nil
+		},
+	)
+	return item, err == nil, err
+}
+
+func (q *Queue) Items() []int {
+	return append([]int(nil), q.items...)
+}
+
+// A simple counter structure
+type Counter struct {
+	value int
+	max   int
+}
+
+func (c *Counter) Increment(tx *Transaction) error {
+	return tx.Do("increment counter",
+		func() error {
+			if c.value >= c.max {
+				return fmt.Errorf("counter exceeds maximum value of %d", c.max)
+			}
+			c.value++
+			return nil
+		},
+		func() error {
+			c.value--
+			return nil
+		},
+	)
+}
+
+func (c *Counter) Value() int {
+	return c.value
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Transaction is an undo log. Each step that changes state registers a
+// compensating action; if the transaction fails, the compensations run in
+// reverse order so the most recent change is undone first.
+type Transaction struct {
+	steps    []step
+	onCommit []func() error
+	done     bool
+}
+
+type step struct {
+	name string
+	undo func() error
+}
+
+// Report describes what a transaction did.
+type Report struct {
+	// Committed lists the steps that completed, in order. On failure
+	// these are the steps that were then compensated.
+	Committed []string
+	// Rollback holds the outcome of each compensation, in undo order.
+	// It is empty when the transaction committed.
+	Rollback []Undo
+	// CleanupErr joins the errors of OnCommit functions. The transaction
+	// still committed; a failed cleanup only leaves litter behind.
+	CleanupErr error
+}
+
+// Undo is the outcome of compensating one step. Err is nil if the
+// compensation succeeded or the step had none.
+type Undo struct {
+	Step string
+	Err  error
+}
+
+// TxError is returned when a transaction fails. Cause is the step's error,
+// or nil if a step panicked; RollbackErrs holds every compensation that
+// itself failed.
+type TxError struct {
+	Cause        error
+	Panic        interface{}
+	RollbackErrs []error
+	Report       Report
+}
+
+func (e *TxError) Error() string {
+	var b strings.Builder
+	if e.Panic != nil {
+		fmt.Fprintf(&b, "transaction panicked: %v", e.Panic)
+	} else {
+		fmt.Fprintf(&b, "transaction failed: %v", e.Cause)
+	}
+	if len(e.RollbackErrs) > 0 {
+		fmt.Fprintf(&b, " (rollback incomplete: %v)", errors.Join(e.RollbackErrs...))
+	}
+	return b.String()
+}
+
+func (e *TxError) Unwrap() []error {
+	errs := append([]error(nil), e.RollbackErrs...)
+	if e.Cause != nil {
+		errs = append([]error{e.Cause}, errs...)
+	}
+	return errs
+}
+
+// Do runs action as a named step. If action succeeds, undo is recorded to
+// be run should the transaction fail later; undo may be nil for steps that
+// change nothing. If action fails, nothing is recorded for it.
+func (t *Transaction) Do(name string, action func() error, undo func() error) error {
+	if t.done {
+		return fmt.Errorf("step %q: transaction already finished", name)
+	}
+	if err := action(); err != nil {
+		return fmt.Errorf("step %q: %w", name, err)
+	}
+	t.steps = append(t.steps, step{name: name, undo: undo})
+	return nil
+}
+
+// OnCommit registers cleanup that only makes sense once the transaction is
+// known to have succeeded, such as deleting backups.
+func (t *Transaction) OnCommit(fn func() error) {
+	t.onCommit = append(t.onCommit, fn)
+}
+
+// Run executes fn in a new transaction. If fn returns an error or panics,
+// every recorded step is compensated and a *TxError is returned; a panic
+// is converted into that error rather than propagated.
+func Run(fn func(tx *Transaction) error) (rep Report, err error) {
+	tx := &Transaction{}
+	defer func() {
+		r := recover()
+		if r == nil && err == nil {
+			rep = tx.commit()
+			return
+		}
+		txErr := &TxError{Cause: err, Panic: r, Report: tx.committed()}
+		tx.rollback(txErr)
+		rep, err = txErr.Report, txErr
+	}()
+	return Report{}, fn(tx)
+}
+
+func (t *Transaction) committed() Report {
+	rep := Report{Committed: make([]string, len(t.steps))}
+	for i, s := range t.steps {
+		rep.Committed[i] = s.name
+	}
+	return rep
+}
+
+func (t *Transaction) commit() Report {
+	t.done = true
+	rep := t.committed()
+	var errs []error
+	for _, fn := range t.onCommit {
+		if err := safeCall(fn); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	rep.CleanupErr = errors.Join(errs...)
+	return rep
+}
+
+func (t *Transaction) rollback(txErr *TxError) {
+	t.done = true
+	for i := len(t.steps) - 1; i >= 0; i-- {
+		s := t.steps[i]
+		var err error
+		if s.undo != nil {
+			if err = safeCall(s.undo); err != nil {
+				err = fmt.Errorf("undo %q: %w", s.name, err)
+				txErr.RollbackErrs = append(txErr.RollbackErrs, err)
+			}
+		}
+		txErr.Report.Rollback = append(txErr.Report.Rollback, Undo{Step: s.name, Err: err})
+	}
+}
+
+// safeCall keeps one panicking compensation or cleanup from skipping the
+// rest.
+func safeCall(fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn()
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// recordingStep registers a step whose undo appends its name to undone.
+func recordingStep(tx *Transaction, name string, undone *[]string) error {
+	return tx.Do(name, func() error { return nil }, func() error {
+		*undone = append(*undone, name)
+		return nil
+	})
+}
+
+func TestRollbackRunsInReverseOrder(t *testing.T) {
+	errBoom := errors.New("boom")
+	var undone []string
+	rep, err := Run(func(tx *Transaction) error {
+		for _, name := range []string{"a", "b", "c"} {
+			if err := recordingStep(tx, name, &undone); err != nil {
+				return err
+			}
+		}
+		return errBoom
+	})
+
+	var txErr *TxError
+	if !errors.As(err, &txErr) || !errors.Is(err, errBoom) || txErr.Panic != nil {
+		t.Fatalf("Run() = %v, want a TxError caused by errBoom", err)
+	}
+	if want := []string{"c", "b", "a"}; !reflect.DeepEqual(undone, want) {
+		t.Errorf("undo order = %v, want %v", undone, want)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(rep.Committed, want) {
+		t.Errorf("Committed = %v, want %v", rep.Committed, want)
+	}
+	want := []Undo{{Step: "c"}, {Step: "b"}, {Step: "a"}}
+	if !reflect.DeepEqual(rep.Rollback, want) {
+		t.Errorf("Rollback = %+v, want %+v", rep.Rollback, want)
+	}
+}
+
+func TestFailedStepIsNotCompensated(t *testing.T) {
+	var undone []string
+	rep, err := Run(func(tx *Transaction) error {
+		if err := recordingStep(tx, "a", &undone); err != nil {
+			return err
+		}
+		return tx.Do("b", func() error { return errors.New("no disk") }, func() error {
+			undone = append(undone, "b")
+			return nil
+		})
+	})
+	if err == nil {
+		t.Fatal("Run() succeeded")
+	}
+	if !reflect.DeepEqual(undone, []string{"a"}) || !reflect.DeepEqual(rep.Committed, []string{"a"}) {
+		t.Errorf("undone = %v, Committed = %v; want only step a", undone, rep.Committed)
+	}
+}
+
+func TestPanicIsConvertedAndRolledBack(t *testing.T) {
+	var undone []string
+	rep, err := Run(func(tx *Transaction) error {
+		if err := recordingStep(tx, "a", &undone); err != nil {
+			return err
+		}
+		panic("kaboom")
+	})
+
+	var txErr *TxError
+	if !errors.As(err, &txErr) {
+		t.Fatalf("Run() = %v, want *TxError", err)
+	}
+	if txErr.Panic != "kaboom" || txErr.Cause != nil {
+		t.Errorf("Panic = %v, Cause = %v", txErr.Panic, txErr.Cause)
+	}
+	if !reflect.DeepEqual(undone, []string{"a"}) || len(rep.Rollback) != 1 {
+		t.Errorf("undone = %v, Rollback = %+v", undone, rep.Rollback)
+	}
+}
+
+func TestFailingUndoDoesNotStopRollback(t *testing.T) {
+	errUndo := errors.New("undo failed")
+	var undone []string
+	rep, err := Run(func(tx *Transaction) error {
+		recordingStep(tx, "a", &undone)
+		tx.Do("b", func() error { return nil }, func() error { return errUndo })
+		tx.Do("c", func() error { return nil }, func() error { panic("undo panicked") })
+		tx.Do("d", func() error { return nil }, nil)
+		return errors.New("boom")
+	})
+
+	var txErr *TxError
+	if !errors.As(err, &txErr) || len(txErr.RollbackErrs) != 2 || !errors.Is(err, errUndo) {
+		t.Fatalf("Run() = %v, want two rollback errors including errUndo", err)
+	}
+	if !reflect.DeepEqual(undone, []string{"a"}) {
+		t.Errorf("undone = %v, want [a]", undone)
+	}
+	var steps []string
+	var failed []string
+	for _, u := range rep.Rollback {
+		steps = append(steps, u.Step)
+		if u.Err != nil {
+			failed = append(failed, u.Step)
+		}
+	}
+	if want := []string{"d", "c", "b", "a"}; !reflect.DeepEqual(steps, want) {
+		t.Errorf("Rollback steps = %v, want %v", steps, want)
+	}
+	if want := []string{"c", "b"}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("failed undos = %v, want %v", failed, want)
+	}
+}
+
+func TestCommitRunsCleanupAndReportsErrors(t *testing.T) {
+	errCleanup := errors.New("cleanup failed")
+	var ran []int
+	rep, err := Run(func(tx *Transaction) error {
+		tx.OnCommit(func() error { ran = append(ran, 1); return errCleanup })
+		tx.OnCommit(func() error { ran = append(ran, 2); return nil })
+		return tx.Do("a", func() error { return nil }, func() error {
+			t.Error("undo ran on commit")
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Run() = %v", err)
+	}
+	if !reflect.DeepEqual(ran, []int{1, 2}) {
+		t.Errorf("cleanups ran = %v, want [1 2]", ran)
+	}
+	if !errors.Is(rep.CleanupErr, errCleanup) {
+		t.Errorf("CleanupErr = %v, want errCleanup", rep.CleanupErr)
+	}
+	if len(rep.Rollback) != 0 || !reflect.DeepEqual(rep.Committed, []string{"a"}) {
+		t.Errorf("report = %+v", rep)
+	}
+}
+
+func TestCleanupSkippedOnFailure(t *testing.T) {
+	Run(func(tx *Transaction) error {
+		tx.OnCommit(func() error {
+			t.Error("cleanup ran after failure")
+			return nil
+		})
+		return errors.New("boom")
+	})
+}
+
+func TestDoAfterFinishFails(t *testing.T) {
+	var saved *Transaction
+	Run(func(tx *Transaction) error {
+		saved = tx
+		return nil
+	})
+	if err := saved.Do("late", func() error { return nil }, nil); err == nil {
+		t.Error("Do after commit succeeded")
+	}
+}
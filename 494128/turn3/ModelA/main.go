@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"turing/494128/turn3/ModelA/reqctx"
+)
+
+var greetings = map[string]string{
+	"en": "Hello",
+	"fr": "Bonjour",
+	"de": "Hallo",
+}
+
+// bearerUserID reads the user ID from "Authorization: Bearer <id>". A real
+// service would verify a token here.
+func bearerUserID(r *http.Request) (int64, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return 0, errors.New("missing bearer token")
+	}
+	id, err := strconv.ParseInt(token, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid bearer token %q", token)
+	}
+	return id, nil
+}
+
+func main() {
+	logger := slog.New(reqctx.NewContextHandler(slog.NewJSONHandler(os.Stderr, nil)))
+	rs := &reqctx.Responder{Logger: logger}
+
+	helloHandler := func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := reqctx.UserIDFromContext(r.Context())
+		if !ok {
+			rs.Error(w, r, errors.New("user ID not found in context"))
+			return
+		}
+		logger.InfoContext(r.Context(), "greeting user")
+		fmt.Fprintf(w, "%s, user %d!\n", greetings[reqctx.LocaleFromContext(r.Context())], userID)
+	}
+
+	itemHandler := func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			rs.Error(w, r, reqctx.BadRequest("item id must be a number"))
+			return
+		}
+		rs.Error(w, r, reqctx.NotFound(fmt.Sprintf("item %d does not exist", id)))
+	}
+
+	auth := reqctx.Authenticate(rs, bearerUserID)
+	mux := http.NewServeMux()
+	mux.Handle("GET /hello", auth(http.HandlerFunc(helloHandler)))
+	mux.HandleFunc("GET /items/{id}", itemHandler)
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("something went badly wrong")
+	})
+
+	handler := reqctx.Chain(mux,
+		reqctx.RequestID(),
+		reqctx.Locale("en", "fr", "de"),
+		reqctx.Recover(rs),
+	)
+
+	logger.Info("server starting", slog.String("addr", ":8080"))
+	if err := http.ListenAndServe(":8080", handler); err != nil {
+		logger.Error("server stopped", slog.Any("error", err))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,51 @@
+// Package reqctx carries per-request values (user ID, request ID, locale)
+// in a context.Context under typed keys, and provides the middleware that
+// sets them, a JSON error responder and a context-aware slog handler.
+package reqctx
+
+import "context"
+
+// key is unexported so no other package can read or overwrite these values
+// except through the accessors below.
+type key int
+
+const (
+	userIDKey key = iota
+	requestIDKey
+	localeKey
+)
+
+// DefaultLocale is returned by LocaleFromContext when none was set.
+const DefaultLocale = "en"
+
+func WithUserID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+// UserIDFromContext returns the authenticated user's ID, if any.
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(userIDKey).(int64)
+	return id, ok
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID, or "" if none was set.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey, locale)
+}
+
+// LocaleFromContext returns the negotiated locale, or DefaultLocale.
+func LocaleFromContext(ctx context.Context) string {
+	if l, ok := ctx.Value(localeKey).(string); ok && l != "" {
+		return l
+	}
+	return DefaultLocale
+}
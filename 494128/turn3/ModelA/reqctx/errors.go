@@ -0,0 +1,98 @@
+package reqctx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+)
+
+// Error codes are part of the API: clients switch on them, so they must
+// not change once published.
+const (
+	CodeBadRequest   = "bad_request"
+	CodeUnauthorized = "unauthorized"
+	CodeNotFound     = "not_found"
+	CodeInternal     = "internal"
+)
+
+// Error is an error meant to be shown to the client.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Err     error // logged, never sent to the client
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return e.Code + ": " + e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+func BadRequest(msg string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeBadRequest, Message: msg}
+}
+
+func Unauthorized(msg string) *Error {
+	return &Error{Status: http.StatusUnauthorized, Code: CodeUnauthorized, Message: msg}
+}
+
+func NotFound(msg string) *Error {
+	return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: msg}
+}
+
+// ErrorBody is the JSON schema of every error response:
+//
+//	{"error": {"code": "not_found", "message": "...", "request_id": "..."}}
+type ErrorBody struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Responder writes errors as JSON and logs them with the request's context
+// fields.
+type Responder struct {
+	Logger *slog.Logger
+}
+
+// Error responds with err. An *Error anywhere in err's chain decides the
+// status and message; anything else is reported as a 500 with a generic
+// message so internal details don't leak.
+func (rs *Responder) Error(w http.ResponseWriter, r *http.Request, err error) {
+	ctx := r.Context()
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		apiErr = &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "internal server error", Err: err}
+	}
+
+	level := slog.LevelWarn
+	if apiErr.Status >= 500 {
+		level = slog.LevelError
+	}
+	rs.Logger.Log(ctx, level, "request failed",
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", apiErr.Status),
+		slog.String("code", apiErr.Code),
+		slog.Any("error", err),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(apiErr.Status)
+	json.NewEncoder(w).Encode(ErrorBody{Error: ErrorDetail{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		RequestID: RequestIDFromContext(ctx),
+	}})
+}
@@ -0,0 +1,42 @@
+package reqctx
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ContextHandler is a slog.Handler that adds the request ID, user ID and
+// locale from the record's context to every record. Log with the *Context
+// methods (InfoContext, ErrorContext, ...) to get them.
+type ContextHandler struct {
+	next slog.Handler
+}
+
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if id, ok := UserIDFromContext(ctx); ok {
+		r.AddAttrs(slog.Int64("user_id", id))
+	}
+	if l, ok := ctx.Value(localeKey).(string); ok {
+		r.AddAttrs(slog.String("locale", l))
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
@@ -0,0 +1,116 @@
+package reqctx
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Middleware wraps a handler.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h so that mws run in the order given: the first one sees the
+// request first.
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+const maxRequestIDLen = 128
+
+// RequestID keeps a well-formed incoming X-Request-ID or generates one,
+// stores it in the context and echoes it in the response.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get("X-Request-ID")
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			w.Header().Set("X-Request-ID", id)
+			next.ServeHTTP(w, r.WithContext(WithRequestID(r.Context(), id)))
+		})
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Locale picks the first language in Accept-Language that is in supported,
+// falling back to supported[0]. Quality values are treated as ordering
+// hints only; browsers already list languages by preference.
+func Locale(supported ...string) Middleware {
+	if len(supported) == 0 {
+		supported = []string{DefaultLocale}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locale := negotiate(r.Header.Get("Accept-Language"), supported)
+			next.ServeHTTP(w, r.WithContext(WithLocale(r.Context(), locale)))
+		})
+	}
+}
+
+func negotiate(header string, supported []string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		for _, s := range supported {
+			base, _, _ := strings.Cut(tag, "-")
+			if strings.EqualFold(tag, s) || strings.EqualFold(base, s) {
+				return s
+			}
+		}
+	}
+	return supported[0]
+}
+
+// Authenticate resolves the caller with auth and stores their user ID. A
+// failing auth gets a 401 through rs; wrap only the routes that need a user.
+func Authenticate(rs *Responder, auth func(*http.Request) (int64, error)) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, err := auth(r)
+			if err != nil {
+				rs.Error(w, r, &Error{Status: http.StatusUnauthorized, Code: CodeUnauthorized, Message: "authentication required", Err: err})
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithUserID(r.Context(), id)))
+		})
+	}
+}
+
+// Recover turns a handler panic into a 500 JSON response.
+func Recover(rs *Responder) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if v := recover(); v != nil {
+					if v == http.ErrAbortHandler {
+						panic(v)
+					}
+					rs.Error(w, r, fmt.Errorf("panic: %v", v))
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// generateInput writes n lines of integers with the occasional bad line.
+func generateInput(n int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		switch {
+		case i > 0 && i%250000 == 0:
+			buf.WriteString("not-a-number\n")
+		case i == n-2:
+			buf.WriteString("99999999999999999999\n")
+		default:
+			buf.WriteString(strconv.Itoa(i*7 - n))
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+func run(name string, p Pipeline, r io.Reader) {
+	var sum int64
+	var count, lastLine int
+	start := time.Now()
+	errs, err := p.Run(context.Background(), r, func(line int, v int64) {
+		if line <= lastLine {
+			panic("results out of order")
+		}
+		lastLine = line
+		sum += v
+		count++
+	})
+	fmt.Printf("%-12s %8d values  sum=%d  %d bad lines  %v\n", name, count, sum, len(errs), time.Since(start).Round(time.Millisecond))
+	if err != nil {
+		fmt.Println("  error:", err)
+	}
+	for i, e := range errs {
+		if i == 3 {
+			fmt.Printf("  ... and %d more\n", len(errs)-i)
+			break
+		}
+		fmt.Println(" ", e)
+	}
+}
+
+func main() {
+	file := flag.String("file", "", "newline-delimited integers to parse (default: generated input)")
+	lines := flag.Int("n", 2000000, "lines to generate when -file is not set")
+	flag.Parse()
+
+	var input []byte
+	if *file != "" {
+		var err error
+		if input, err = os.ReadFile(*file); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	} else {
+		input = generateInput(*lines)
+	}
+
+	run("sequential", Pipeline{Workers: 1}, bytes.NewReader(input))
+	run(fmt.Sprintf("parallel(%d)", runtime.GOMAXPROCS(0)), Pipeline{}, bytes.NewReader(input))
+	run("atoi", Pipeline{Parse: parseAtoi}, bytes.NewReader(input))
+}
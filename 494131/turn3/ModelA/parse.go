@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+var (
+	ErrSyntax = errors.New("invalid integer")
+	ErrRange  = errors.New("integer out of range")
+)
+
+// parseSscanf is the original approach, kept for the benchmarks. Sscanf
+// goes through reflection and a generic scanner, and accepts trailing
+// garbage ("12abc" parses as 12).
+func parseSscanf(s string) (int64, error) {
+	var n int64
+	if _, err := fmt.Sscanf(s, "%d", &n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// parseAtoi uses strconv, which has a fast path for short decimal input.
+func parseAtoi(b []byte) (int64, error) {
+	n, err := strconv.ParseInt(string(b), 10, 64)
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) && numErr.Err == strconv.ErrRange {
+			return 0, ErrRange
+		}
+		return 0, ErrSyntax
+	}
+	return n, nil
+}
+
+// parseBytes is a hand-rolled decimal parser working directly on the line
+// bytes: an optional sign followed by digits, nothing else.
+func parseBytes(b []byte) (int64, error) {
+	if len(b) == 0 {
+		return 0, ErrSyntax
+	}
+	neg := false
+	switch b[0] {
+	case '-':
+		neg = true
+		b = b[1:]
+	case '+':
+		b = b[1:]
+	}
+	if len(b) == 0 {
+		return 0, ErrSyntax
+	}
+
+	// Accumulate as a negative number so math.MinInt64 fits.
+	var n int64
+	for _, c := range b {
+		d := int64(c) - '0'
+		if d < 0 || d > 9 {
+			return 0, ErrSyntax
+		}
+		if n < (math.MinInt64+d)/10 {
+			return 0, ErrRange
+		}
+		n = n*10 - d
+	}
+	if !neg {
+		if n == math.MinInt64 {
+			return 0, ErrRange
+		}
+		n = -n
+	}
+	return n, nil
+}
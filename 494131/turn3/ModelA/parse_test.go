@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		err  error
+	}{
+		{"0", 0, nil},
+		{"42", 42, nil},
+		{"-17", -17, nil},
+		{"+8", 8, nil},
+		{"9223372036854775807", math.MaxInt64, nil},
+		{"-9223372036854775808", math.MinInt64, nil},
+		{"9223372036854775808", 0, ErrRange},
+		{"-9223372036854775809", 0, ErrRange},
+		{"", 0, ErrSyntax},
+		{"-", 0, ErrSyntax},
+		{"12a", 0, ErrSyntax},
+		{" 1", 0, ErrSyntax},
+	}
+	for _, tt := range tests {
+		got, err := parseBytes([]byte(tt.in))
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("parseBytes(%q) = %d, %v; want %d, %v", tt.in, got, err, tt.want, tt.err)
+		}
+		if tt.err == nil {
+			if n, _ := parseAtoi([]byte(tt.in)); n != tt.want {
+				t.Errorf("parseAtoi(%q) = %d, want %d", tt.in, n, tt.want)
+			}
+		}
+	}
+}
+
+func TestPipelineOrderAndLineNumbers(t *testing.T) {
+	input := []byte("1\n2\n\nbad\r\n3\n4\n5\n6\n7\n8")
+	var got []int64
+	var lines []int
+	// A tiny chunk size forces many chunks through several workers.
+	errs, err := Pipeline{Workers: 4, ChunkSize: 3}.Run(context.Background(), bytes.NewReader(input), func(line int, v int64) {
+		lines = append(lines, line)
+		got = append(got, v)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int64{1, 2, 3, 4, 5, 6, 7, 8}
+	wantLines := []int{1, 2, 5, 6, 7, 8, 9, 10}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] || lines[i] != wantLines[i] {
+			t.Fatalf("got values %v at lines %v, want %v at %v", got, lines, want, wantLines)
+		}
+	}
+	if len(errs) != 1 || errs[0].Line != 4 || errs[0].Text != "bad" {
+		t.Fatalf("errs = %v, want one error on line 4", errs)
+	}
+}
+
+var sink int64
+
+func benchLines() [][]byte {
+	lines := make([][]byte, 1024)
+	for i := range lines {
+		lines[i] = []byte(strconv.Itoa(i*7919 - 500000))
+	}
+	return lines
+}
+
+func BenchmarkParse(b *testing.B) {
+	lines := benchLines()
+	strs := make([]string, len(lines))
+	for i, l := range lines {
+		strs[i] = string(l)
+	}
+
+	b.Run("Sscanf", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			n, _ := parseSscanf(strs[i%len(strs)])
+			sink += n
+		}
+	})
+	b.Run("Atoi", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			n, _ := parseAtoi(lines[i%len(lines)])
+			sink += n
+		}
+	})
+	b.Run("Bytes", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			n, _ := parseBytes(lines[i%len(lines)])
+			sink += n
+		}
+	})
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	input := generateInput(200000)
+	for _, bc := range []struct {
+		name string
+		p    Pipeline
+	}{
+		{"Sequential", Pipeline{Workers: 1}},
+		{"Parallel", Pipeline{}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(input)))
+			for i := 0; i < b.N; i++ {
+				bc.p.Run(context.Background(), bytes.NewReader(input), func(_ int, v int64) { sink += v })
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+)
+
+// LineError is a line that failed to parse. Line is 1-based.
+type LineError struct {
+	Line int
+	Text string
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %q: %v", e.Line, e.Text, e.Err)
+}
+
+func (e *LineError) Unwrap() error { return e.Err }
+
+// Pipeline parses newline-delimited integers. Input is cut into chunks of
+// whole lines that are parsed in parallel; results are delivered in input
+// order.
+type Pipeline struct {
+	Workers   int                         // defaults to GOMAXPROCS
+	ChunkSize int                         // bytes per chunk, defaults to 256 KiB
+	Parse     func([]byte) (int64, error) // defaults to parseBytes
+}
+
+type chunk struct {
+	data      []byte
+	firstLine int
+	result    chan chunkResult
+}
+
+type chunkResult struct {
+	values []int64
+	lines  []int
+	errs   []*LineError
+}
+
+// Run parses r and calls emit for every valid line in order. Blank lines
+// are skipped. Lines that fail to parse are returned, also in order; the
+// error is non-nil only if reading r fails or ctx is canceled.
+func (p Pipeline) Run(ctx context.Context, r io.Reader, emit func(line int, v int64)) ([]*LineError, error) {
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunkSize := p.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = 256 << 10
+	}
+	parse := p.Parse
+	if parse == nil {
+		parse = parseBytes
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan *chunk)
+	// ordered holds chunks in input order; its capacity bounds how far
+	// parsing may run ahead of emit.
+	ordered := make(chan *chunk, workers*2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range jobs {
+				c.result <- parseChunk(c, parse)
+			}
+		}()
+	}
+
+	var readErr error
+	go func() {
+		defer close(ordered)
+		defer close(jobs)
+		readErr = splitChunks(ctx, r, chunkSize, func(c *chunk) bool {
+			select {
+			case ordered <- c:
+			case <-ctx.Done():
+				return false
+			}
+			select {
+			case jobs <- c:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	var lineErrs []*LineError
+	for c := range ordered {
+		var res chunkResult
+		select {
+		case res = <-c.result:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		for i, v := range res.values {
+			emit(res.lines[i], v)
+		}
+		lineErrs = append(lineErrs, res.errs...)
+	}
+	cancel()
+	// Drain so the reader can exit, then wait for the workers.
+	for range ordered {
+	}
+	wg.Wait()
+
+	if err := parent.Err(); err != nil {
+		return lineErrs, err
+	}
+	return lineErrs, readErr
+}
+
+// splitChunks reads r and hands out chunks that end on a line boundary.
+// A line longer than size grows the chunk rather than being split.
+func splitChunks(ctx context.Context, r io.Reader, size int, send func(*chunk) bool) error {
+	line := 1
+	var carry []byte
+	for {
+		buf := make([]byte, len(carry), len(carry)+size)
+		copy(buf, carry)
+		n, err := io.ReadFull(r, buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		eof := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !eof {
+			return err
+		}
+
+		data := buf
+		carry = nil
+		if !eof {
+			i := bytes.LastIndexByte(buf, '\n')
+			if i < 0 {
+				carry = buf // no complete line yet; read more
+				continue
+			}
+			data, carry = buf[:i+1], buf[i+1:]
+		}
+		if len(data) > 0 {
+			c := &chunk{data: data, firstLine: line, result: make(chan chunkResult, 1)}
+			line += bytes.Count(data, []byte{'\n'})
+			if !send(c) {
+				return ctx.Err()
+			}
+		}
+		if eof {
+			return nil
+		}
+	}
+}
+
+func parseChunk(c *chunk, parse func([]byte) (int64, error)) chunkResult {
+	var res chunkResult
+	data := c.data
+	for line := c.firstLine; len(data) > 0; line++ {
+		var text []byte
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			text, data = data[:i], data[i+1:]
+		} else {
+			text, data = data, nil
+		}
+		text = bytes.TrimSuffix(text, []byte{'\r'})
+		if len(text) == 0 {
+			continue
+		}
+		v, err := parse(text)
+		if err != nil {
+			res.errs = append(res.errs, &LineError{Line: line, Text: string(text), Err: err})
+			continue
+		}
+		res.values = append(res.values, v)
+		res.lines = append(res.lines, line)
+	}
+	return res
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	stripeBits     = 6 // 64 stripes
+	stripeCount    = 1 << stripeBits
+	initialBuckets = 8
+	maxLoad        = 0.75
+	// migrateStep is how many old buckets each write moves into the new
+	// table while a stripe is resizing.
+	migrateStep = 4
+)
+
+type entry[K comparable, V any] struct {
+	key  K
+	val  V
+	hash uint64
+	next *entry[K, V]
+}
+
+// stripe is an independently locked chained hash table. When it grows, the
+// old table is kept and drained a few buckets per write, so no single
+// operation pays for rehashing the whole stripe.
+type stripe[K comparable, V any] struct {
+	mu      sync.RWMutex
+	buckets []*entry[K, V]
+	old     []*entry[K, V] // non-nil while resizing
+	moved   int            // old buckets [0, moved) are already migrated
+	count   int
+	_       [40]byte // keep neighbouring stripes' locks off one cache line
+}
+
+// ConcurrentMap is a hash map safe for concurrent use. Keys are spread over
+// a fixed set of lock stripes, so operations on different stripes never
+// contend, and each stripe resizes incrementally.
+type ConcurrentMap[K comparable, V any] struct {
+	hash    func(K) uint64
+	stripes [stripeCount]stripe[K, V]
+	size    atomic.Int64
+}
+
+// NewConcurrentMap returns an empty map. hash may be nil to use a default
+// that handles strings and numbers directly and any other comparable key
+// through reflection.
+func NewConcurrentMap[K comparable, V any](hash func(K) uint64) *ConcurrentMap[K, V] {
+	if hash == nil {
+		hash = defaultHash[K]
+	}
+	m := &ConcurrentMap[K, V]{hash: hash}
+	for i := range m.stripes {
+		m.stripes[i].buckets = make([]*entry[K, V], initialBuckets)
+	}
+	return m
+}
+
+func (m *ConcurrentMap[K, V]) stripeFor(h uint64) *stripe[K, V] {
+	// The stripe uses the top bits and buckets use the low bits, so keys
+	// in one stripe still spread over all its buckets.
+	return &m.stripes[h>>(64-stripeBits)]
+}
+
+// Len returns the number of entries. It is O(1).
+func (m *ConcurrentMap[K, V]) Len() int {
+	return int(m.size.Load())
+}
+
+func (m *ConcurrentMap[K, V]) Load(key K) (V, bool) {
+	h := m.hash(key)
+	s := m.stripeFor(h)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if e := s.find(key, h); e != nil {
+		return e.val, true
+	}
+	var zero V
+	return zero, false
+}
+
+func (m *ConcurrentMap[K, V]) Store(key K, val V) {
+	m.Compute(key, func(V, bool) (V, bool) { return val, true })
+}
+
+// LoadOrStore returns the existing value for key if present. Otherwise it
+// stores and returns val. loaded reports which happened.
+func (m *ConcurrentMap[K, V]) LoadOrStore(key K, val V) (actual V, loaded bool) {
+	h := m.hash(key)
+	s := m.stripeFor(h)
+
+	// Most calls for an existing key can be answered under the read lock.
+	s.mu.RLock()
+	if e := s.find(key, h); e != nil {
+		actual = e.val
+		s.mu.RUnlock()
+		return actual, true
+	}
+	s.mu.RUnlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.migrate()
+	if e := s.find(key, h); e != nil {
+		return e.val, true
+	}
+	s.insert(&entry[K, V]{key: key, val: val, hash: h})
+	m.size.Add(1)
+	return val, false
+}
+
+func (m *ConcurrentMap[K, V]) Delete(key K) {
+	m.LoadAndDelete(key)
+}
+
+func (m *ConcurrentMap[K, V]) LoadAndDelete(key K) (V, bool) {
+	h := m.hash(key)
+	s := m.stripeFor(h)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.migrate()
+	if e := s.remove(key, h); e != nil {
+		m.size.Add(-1)
+		return e.val, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Compute atomically updates key. fn receives the current value and
+// whether it exists, and returns the new value and whether to keep it;
+// returning keep=false deletes the key. fn runs with the key's stripe
+// locked, so it must be quick and must not call back into the map.
+func (m *ConcurrentMap[K, V]) Compute(key K, fn func(old V, loaded bool) (val V, keep bool)) (V, bool) {
+	h := m.hash(key)
+	s := m.stripeFor(h)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.migrate()
+
+	e := s.find(key, h)
+	var old V
+	if e != nil {
+		old = e.val
+	}
+	val, keep := fn(old, e != nil)
+	switch {
+	case keep && e != nil:
+		e.val = val
+	case keep:
+		s.insert(&entry[K, V]{key: key, val: val, hash: h})
+		m.size.Add(1)
+	case e != nil:
+		s.remove(key, h)
+		m.size.Add(-1)
+	}
+	return val, keep
+}
+
+// Range calls f for each entry until f returns false. Like sync.Map.Range
+// it does not see a consistent snapshot of the whole map: each stripe is
+// copied under its lock and f is called without holding any lock, so f
+// may safely modify the map.
+func (m *ConcurrentMap[K, V]) Range(f func(key K, val V) bool) {
+	var keys []K
+	var vals []V
+	for i := range m.stripes {
+		s := &m.stripes[i]
+		keys, vals = keys[:0], vals[:0]
+		s.mu.RLock()
+		for _, tbl := range [][]*entry[K, V]{s.buckets, s.old} {
+			for _, e := range tbl {
+				for ; e != nil; e = e.next {
+					keys = append(keys, e.key)
+					vals = append(vals, e.val)
+				}
+			}
+		}
+		s.mu.RUnlock()
+		for j := range keys {
+			if !f(keys[j], vals[j]) {
+				return
+			}
+		}
+	}
+}
+
+func (s *stripe[K, V]) find(key K, h uint64) *entry[K, V] {
+	for e := s.buckets[h&uint64(len(s.buckets)-1)]; e != nil; e = e.next {
+		if e.hash == h && e.key == key {
+			return e
+		}
+	}
+	if s.old != nil {
+		if i := int(h & uint64(len(s.old)-1)); i >= s.moved {
+			for e := s.old[i]; e != nil; e = e.next {
+				if e.hash == h && e.key == key {
+					return e
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// insert adds a new entry; the caller has checked the key is absent.
+func (s *stripe[K, V]) insert(e *entry[K, V]) {
+	if s.old == nil && float64(s.count+1) > maxLoad*float64(len(s.buckets)) {
+		s.old = s.buckets
+		s.buckets = make([]*entry[K, V], len(s.old)*2)
+		s.moved = 0
+		s.migrate()
+	}
+	i := e.hash & uint64(len(s.buckets)-1)
+	e.next = s.buckets[i]
+	s.buckets[i] = e
+	s.count++
+}
+
+func (s *stripe[K, V]) remove(key K, h uint64) *entry[K, V] {
+	unlink := func(tbl []*entry[K, V]) *entry[K, V] {
+		i := h & uint64(len(tbl)-1)
+		for p := &tbl[i]; *p != nil; p = &(*p).next {
+			if e := *p; e.hash == h && e.key == key {
+				*p = e.next
+				return e
+			}
+		}
+		return nil
+	}
+	e := unlink(s.buckets)
+	if e == nil && s.old != nil && int(h&uint64(len(s.old)-1)) >= s.moved {
+		e = unlink(s.old)
+	}
+	if e != nil {
+		s.count--
+	}
+	return e
+}
+
+// migrate moves up to migrateStep old buckets into the current table.
+func (s *stripe[K, V]) migrate() {
+	if s.old == nil {
+		return
+	}
+	mask := uint64(len(s.buckets) - 1)
+	for n := 0; n < migrateStep && s.moved < len(s.old); n++ {
+		for e := s.old[s.moved]; e != nil; {
+			next := e.next
+			i := e.hash & mask
+			e.next = s.buckets[i]
+			s.buckets[i] = e
+			e = next
+		}
+		s.old[s.moved] = nil
+		s.moved++
+	}
+	if s.moved == len(s.old) {
+		s.old, s.moved = nil, 0
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConcurrentMapResizeUnderLoad(t *testing.T) {
+	m := NewConcurrentMap[string, int](nil)
+	const workers, perWorker = 8, 5000
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				k := strconv.Itoa(w*perWorker + i)
+				m.Store(k, i)
+				if v, ok := m.Load(k); !ok || v != i {
+					t.Errorf("Load(%s) = %d, %v right after Store", k, v, ok)
+					return
+				}
+				if i%2 == 1 {
+					m.Delete(k)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if got, want := m.Len(), workers*perWorker/2; got != want {
+		t.Fatalf("Len = %d, want %d", got, want)
+	}
+	seen := 0
+	m.Range(func(k string, v int) bool {
+		if v%2 == 1 {
+			t.Errorf("deleted key %s still present", k)
+		}
+		seen++
+		return true
+	})
+	if seen != m.Len() {
+		t.Fatalf("Range saw %d entries, Len = %d", seen, m.Len())
+	}
+}
+
+func TestConcurrentMapLoadOrStoreAndCompute(t *testing.T) {
+	m := NewConcurrentMap[int, int](nil)
+	if v, loaded := m.LoadOrStore(1, 10); loaded || v != 10 {
+		t.Fatalf("LoadOrStore new = %d, %v", v, loaded)
+	}
+	if v, loaded := m.LoadOrStore(1, 20); !loaded || v != 10 {
+		t.Fatalf("LoadOrStore existing = %d, %v", v, loaded)
+	}
+
+	// Concurrent increments through Compute must not lose updates.
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				m.Compute(2, func(old int, _ bool) (int, bool) { return old + 1, true })
+			}
+		}()
+	}
+	wg.Wait()
+	if v, _ := m.Load(2); v != 8000 {
+		t.Fatalf("counter = %d, want 8000", v)
+	}
+
+	m.Compute(2, func(int, bool) (int, bool) { return 0, false })
+	if _, ok := m.Load(2); ok || m.Len() != 1 {
+		t.Fatalf("Compute keep=false did not delete: ok=%v len=%d", ok, m.Len())
+	}
+}
+
+// mutexMap is the baseline: one lock around a built-in map.
+type mutexMap struct {
+	mu sync.RWMutex
+	m  map[int]int
+}
+
+func (m *mutexMap) Store(k, v int) {
+	m.mu.Lock()
+	m.m[k] = v
+	m.mu.Unlock()
+}
+
+func (m *mutexMap) Load(k int) (int, bool) {
+	m.mu.RLock()
+	v, ok := m.m[k]
+	m.mu.RUnlock()
+	return v, ok
+}
+
+func (m *mutexMap) Delete(k int) {
+	m.mu.Lock()
+	delete(m.m, k)
+	m.mu.Unlock()
+}
+
+// syncMap adapts sync.Map to the benchmarked interface.
+type syncMap struct{ m sync.Map }
+
+func (m *syncMap) Store(k, v int) { m.m.Store(k, v) }
+func (m *syncMap) Delete(k int)   { m.m.Delete(k) }
+func (m *syncMap) Load(k int) (int, bool) {
+	v, ok := m.m.Load(k)
+	if !ok {
+		return 0, false
+	}
+	return v.(int), true
+}
+
+type benchMap interface {
+	Store(k, v int)
+	Load(k int) (int, bool)
+	Delete(k int)
+}
+
+const benchKeys = 1 << 16
+
+var benchImpls = []struct {
+	name string
+	new  func() benchMap
+}{
+	{"ConcurrentMap", func() benchMap { return NewConcurrentMap[int, int](nil) }},
+	{"SyncMap", func() benchMap { return &syncMap{} }},
+	{"MutexMap", func() benchMap { return &mutexMap{m: make(map[int]int)} }},
+}
+
+func prefilled(newMap func() benchMap) benchMap {
+	m := newMap()
+	for i := 0; i < benchKeys; i++ {
+		m.Store(i, i)
+	}
+	return m
+}
+
+func BenchmarkInsert(b *testing.B) {
+	for _, impl := range benchImpls {
+		b.Run(impl.name, func(b *testing.B) {
+			m := impl.new()
+			// Each goroutine inserts its own key range; starting them all
+			// at 0 would measure overwrites after the first one.
+			var worker atomic.Int64
+			b.RunParallel(func(pb *testing.PB) {
+				i := int(worker.Add(1)) << 32
+				for pb.Next() {
+					m.Store(i, i)
+					i++
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	for _, impl := range benchImpls {
+		b.Run(impl.name, func(b *testing.B) {
+			m := prefilled(impl.new)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					m.Load(i & (benchKeys - 1))
+					i++
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkDelete(b *testing.B) {
+	for _, impl := range benchImpls {
+		b.Run(impl.name, func(b *testing.B) {
+			m := prefilled(impl.new)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					// Re-store so later iterations still have something
+					// to delete.
+					k := i & (benchKeys - 1)
+					m.Delete(k)
+					m.Store(k, i)
+					i++
+				}
+			})
+		})
+	}
+}
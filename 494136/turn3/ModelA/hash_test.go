@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+type node struct {
+	name  string
+	score float64
+}
+
+func TestPointerKeySurvivesMutation(t *testing.T) {
+	m := NewConcurrentMap[*node, int](nil)
+	a, b := &node{name: "a"}, &node{name: "a"}
+	m.Store(a, 1)
+	m.Store(b, 2)
+
+	a.name, a.score = "renamed", 3.5
+	if v, ok := m.Load(a); !ok || v != 1 {
+		t.Errorf("Load(mutated a) = %d, %v; want 1, true", v, ok)
+	}
+	if v, ok := m.Load(b); !ok || v != 2 {
+		t.Errorf("Load(b) = %d, %v; want 2, true", v, ok)
+	}
+	if m.Len() != 2 {
+		t.Errorf("Len = %d, want 2 distinct pointers", m.Len())
+	}
+}
+
+func TestFloatZeroKeys(t *testing.T) {
+	negZero := math.Copysign(0, -1)
+
+	m := NewConcurrentMap[float64, string](nil)
+	m.Store(0.0, "zero")
+	if v, ok := m.Load(negZero); !ok || v != "zero" {
+		t.Errorf("Load(-0.0) = %q, %v; want the +0.0 entry", v, ok)
+	}
+	m.Store(negZero, "negative zero")
+	if m.Len() != 1 {
+		t.Errorf("Len = %d after storing 0.0 and -0.0, want 1", m.Len())
+	}
+
+	m32 := NewConcurrentMap[float32, int](nil)
+	m32.Store(0, 1)
+	if _, ok := m32.Load(float32(negZero)); !ok {
+		t.Error("float32 -0 not found under +0")
+	}
+
+	type point struct{ x, y float64 }
+	mp := NewConcurrentMap[point, int](nil)
+	mp.Store(point{0, 1}, 1)
+	if _, ok := mp.Load(point{negZero, 1}); !ok {
+		t.Error("struct with -0 field not found under +0")
+	}
+}
+
+func TestDefaultHashMatchesEquality(t *testing.T) {
+	type key struct {
+		s string
+		n int8
+		p *node
+		i any
+	}
+	shared := &node{}
+	pairs := [][2]key{
+		{{"a", 1, shared, 2}, {"a", 1, shared, 2}},
+		{{"", -1, nil, nil}, {"", -1, nil, nil}},
+		{{"x", 0, nil, "y"}, {"x", 0, nil, "y"}},
+	}
+	for _, p := range pairs {
+		if defaultHash(p[0]) != defaultHash(p[1]) {
+			t.Errorf("equal keys %+v hash differently", p[0])
+		}
+	}
+
+	if defaultHash(key{p: shared}) == defaultHash(key{p: &node{}}) {
+		t.Error("distinct pointers with equal targets hash the same")
+	}
+	if defaultHash([2]string{"ab", "c"}) == defaultHash([2]string{"a", "bc"}) {
+		t.Error("string boundaries are not part of the hash")
+	}
+}
+
+func TestSmallIntegerKeys(t *testing.T) {
+	m8 := NewConcurrentMap[uint8, int](nil)
+	m16 := NewConcurrentMap[int16, int](nil)
+	mp := NewConcurrentMap[uintptr, int](nil)
+	for i := 0; i < 256; i++ {
+		m8.Store(uint8(i), i)
+		m16.Store(int16(i-128), i)
+		mp.Store(uintptr(i), i)
+	}
+	for i := 0; i < 256; i++ {
+		if v, ok := m8.Load(uint8(i)); !ok || v != i {
+			t.Fatalf("uint8 Load(%d) = %d, %v", i, v, ok)
+		}
+		if v, ok := m16.Load(int16(i - 128)); !ok || v != i {
+			t.Fatalf("int16 Load(%d) = %d, %v", i-128, v, ok)
+		}
+		if v, ok := mp.Load(uintptr(i)); !ok || v != i {
+			t.Fatalf("uintptr Load(%d) = %d, %v", i, v, ok)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+func main() {
+	m := NewConcurrentMap[int, string](nil)
+	const n = 1_000_000
+	workers := runtime.GOMAXPROCS(0)
+
+	timed := func(name string, op func(i int)) {
+		start := time.Now()
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := w; i < n; i += workers {
+					op(i)
+				}
+			}(w)
+		}
+		wg.Wait()
+		fmt.Printf("%-8s %d ops on %d goroutines: %v (len %d)\n", name, n, workers, time.Since(start), m.Len())
+	}
+
+	timed("insert", func(i int) { m.Store(i, "value") })
+	timed("lookup", func(i int) { m.Load(i) })
+	timed("compute", func(i int) {
+		m.Compute(i, func(old string, ok bool) (string, bool) { return "updated", ok })
+	})
+
+	updated := 0
+	m.Range(func(_ int, v string) bool {
+		if v == "updated" {
+			updated++
+		}
+		return true
+	})
+	fmt.Println("range saw", updated, "updated entries")
+
+	timed("delete", func(i int) { m.Delete(i) })
+
+	fmt.Println("Run `go test -bench .` to compare with sync.Map and a mutex map.")
+}
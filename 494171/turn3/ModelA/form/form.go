@@ -0,0 +1,286 @@
+// Package form is a UI-independent form model: field definitions, their
+// values and validation, and the state of a submission. A view renders a
+// Form and forwards user input to it; everything the view shows (values,
+// errors, whether submit is possible) comes from the model, so the logic
+// can be tested without a UI.
+package form
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Kind tells a view which input widget to use.
+type Kind int
+
+const (
+	Text Kind = iota
+	EmailAddress
+	Password
+	MultiLine
+)
+
+// Field defines one input.
+type Field struct {
+	Name        string
+	Label       string
+	Placeholder string
+	Kind        Kind
+	Validators  []Validator
+}
+
+// State is where the form is in its submission lifecycle.
+type State int
+
+const (
+	Editing State = iota
+	Submitting
+	Submitted
+	Failed
+)
+
+func (s State) String() string {
+	switch s {
+	case Editing:
+		return "editing"
+	case Submitting:
+		return "submitting"
+	case Submitted:
+		return "submitted"
+	case Failed:
+		return "failed"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+var (
+	ErrInvalid      = errors.New("form has invalid fields")
+	ErrInProgress   = errors.New("form is already being submitted")
+	ErrUnknownField = errors.New("unknown field")
+)
+
+// Form holds the values and validation state of a set of fields. It is
+// safe for concurrent use, so a submit handler may run on another
+// goroutine than the UI.
+type Form struct {
+	fields []Field
+	index  map[string]int
+
+	mu        sync.Mutex
+	values    map[string]string
+	touched   map[string]bool
+	attempted bool // a submit was tried; show every error from now on
+	state     State
+	submitErr error
+	onChange  []func()
+}
+
+// New creates a form with fields in display order. It panics on duplicate
+// or empty field names, which are programming errors.
+func New(fields ...Field) *Form {
+	f := &Form{
+		fields:  fields,
+		index:   make(map[string]int, len(fields)),
+		values:  make(map[string]string, len(fields)),
+		touched: make(map[string]bool, len(fields)),
+	}
+	for i, fd := range fields {
+		if fd.Name == "" {
+			panic("form: field with empty name")
+		}
+		if _, dup := f.index[fd.Name]; dup {
+			panic("form: duplicate field " + fd.Name)
+		}
+		f.index[fd.Name] = i
+	}
+	return f
+}
+
+// Fields returns the field definitions in display order.
+func (f *Form) Fields() []Field {
+	return append([]Field(nil), f.fields...)
+}
+
+// OnChange registers fn to be called after any value or state change.
+// Views use it to refresh; fn is called without the form's lock held.
+func (f *Form) OnChange(fn func()) {
+	f.mu.Lock()
+	f.onChange = append(f.onChange, fn)
+	f.mu.Unlock()
+}
+
+func (f *Form) notify() {
+	f.mu.Lock()
+	fns := append([]func(){}, f.onChange...)
+	f.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// Set updates a field's value and marks it touched. Editing after a
+// finished submission returns the form to Editing.
+func (f *Form) Set(name, value string) error {
+	if _, ok := f.index[name]; !ok {
+		return fmt.Errorf("%w %q", ErrUnknownField, name)
+	}
+	f.mu.Lock()
+	changed := f.values[name] != value
+	f.values[name] = value
+	f.touched[name] = true
+	if changed && (f.state == Submitted || f.state == Failed) {
+		f.state, f.submitErr = Editing, nil
+	}
+	f.mu.Unlock()
+	f.notify()
+	return nil
+}
+
+func (f *Form) Value(name string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.values[name]
+}
+
+// Values returns a copy of every field's value.
+func (f *Form) Values() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.snapshot()
+}
+
+// snapshot copies the values; f.mu must be held.
+func (f *Form) snapshot() map[string]string {
+	out := make(map[string]string, len(f.fields))
+	for _, fd := range f.fields {
+		out[fd.Name] = f.values[fd.Name]
+	}
+	return out
+}
+
+// FieldError returns the first validation error for the field's current
+// value, whether or not it should be shown yet.
+func (f *Form) FieldError(name string) error {
+	i, ok := f.index[name]
+	if !ok {
+		return fmt.Errorf("%w %q", ErrUnknownField, name)
+	}
+	return check(f.fields[i], f.Value(name))
+}
+
+// check returns the first error fd's validators report for v.
+func check(fd Field, v string) error {
+	for _, validate := range fd.Validators {
+		if err := validate(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VisibleError is the error a view should display under the field: errors
+// stay hidden until the user has edited the field or tried to submit, so
+// an untouched form doesn't open covered in complaints.
+func (f *Form) VisibleError(name string) error {
+	f.mu.Lock()
+	show := f.touched[name] || f.attempted
+	f.mu.Unlock()
+	if !show {
+		return nil
+	}
+	return f.FieldError(name)
+}
+
+// Errors validates every field and returns the failures by field name.
+func (f *Form) Errors() map[string]error {
+	return f.validate(f.Values())
+}
+
+// validate runs every field's validators against values, a snapshot taken
+// in one critical section so the fields are checked as a consistent set.
+func (f *Form) validate(values map[string]string) map[string]error {
+	errs := make(map[string]error)
+	for _, fd := range f.fields {
+		if err := check(fd, values[fd.Name]); err != nil {
+			errs[fd.Name] = err
+		}
+	}
+	return errs
+}
+
+func (f *Form) Valid() bool {
+	return len(f.Errors()) == 0
+}
+
+// State returns the submission state and, when Failed, the error the
+// submit handler returned.
+func (f *Form) State() (State, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state, f.submitErr
+}
+
+// Submit validates the form and, if valid, calls handler with the trimmed
+// values. It returns ErrInvalid (wrapping the field errors) without
+// calling handler if any field is invalid, and ErrInProgress if another
+// Submit is still running.
+func (f *Form) Submit(handler func(values map[string]string) error) error {
+	// Claim the submission in the same critical section as the check, so
+	// two concurrent calls cannot both get past it, and snapshot the
+	// values there too, so what is validated is exactly what handler gets.
+	f.mu.Lock()
+	if f.state == Submitting {
+		f.mu.Unlock()
+		return ErrInProgress
+	}
+	prevState, prevErr := f.state, f.submitErr
+	f.state = Submitting
+	f.attempted = true
+	values := f.snapshot()
+	f.mu.Unlock()
+
+	if errs := f.validate(values); len(errs) > 0 {
+		f.mu.Lock()
+		f.state, f.submitErr = prevState, prevErr
+		f.mu.Unlock()
+		f.notify()
+		var list []string
+		for _, fd := range f.fields {
+			if err, ok := errs[fd.Name]; ok {
+				list = append(list, fd.Label+": "+err.Error())
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrInvalid, strings.Join(list, "; "))
+	}
+
+	f.notify()
+
+	for k, v := range values {
+		values[k] = strings.TrimSpace(v)
+	}
+	err := handler(values)
+
+	f.mu.Lock()
+	if err != nil {
+		f.state, f.submitErr = Failed, err
+	} else {
+		f.state, f.submitErr = Submitted, nil
+	}
+	f.mu.Unlock()
+	f.notify()
+	return err
+}
+
+// Reset clears every value and returns the form to a pristine Editing
+// state.
+func (f *Form) Reset() {
+	f.mu.Lock()
+	f.values = make(map[string]string, len(f.fields))
+	f.touched = make(map[string]bool, len(f.fields))
+	f.attempted = false
+	f.state, f.submitErr = Editing, nil
+	f.mu.Unlock()
+	f.notify()
+}
@@ -0,0 +1,172 @@
+package form
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestValidators(t *testing.T) {
+	tests := []struct {
+		name  string
+		v     Validator
+		value string
+		ok    bool
+	}{
+		{"required empty", Required(), "", false},
+		{"required spaces", Required(), "   ", false},
+		{"required set", Required(), "x", true},
+		{"min short", MinLength(3), "ab", false},
+		{"min empty passes", MinLength(3), "", true},
+		{"min runes", MinLength(3), "äöü", true},
+		{"max long", MaxLength(3), "abcd", false},
+		{"max ok", MaxLength(3), "abc", true},
+		{"min trims", MinLength(3), "  ab  ", false},
+		{"min blank passes", MinLength(3), "   ", true},
+		{"max trims", MaxLength(3), " abc ", true},
+		{"email ok", Email(), "ann@example.com", true},
+		{"email trimmed", Email(), " ann@example.com ", true},
+		{"email no at", Email(), "ann.example.com", false},
+		{"email no tld", Email(), "ann@localhost", false},
+		{"email display name", Email(), "Ann <ann@example.com>", false},
+		{"email empty passes", Email(), "", true},
+	}
+	for _, tt := range tests {
+		if err := tt.v(tt.value); (err == nil) != tt.ok {
+			t.Errorf("%s: %q gave %v", tt.name, tt.value, err)
+		}
+	}
+}
+
+func newTestForm() *Form {
+	return New(
+		Field{Name: "name", Label: "Name", Validators: []Validator{Required()}},
+		Field{Name: "email", Label: "Email", Validators: []Validator{Required(), Email()}},
+	)
+}
+
+func TestErrorsHiddenUntilTouchedOrSubmitted(t *testing.T) {
+	f := newTestForm()
+	if err := f.VisibleError("name"); err != nil {
+		t.Fatalf("untouched field shows %v", err)
+	}
+
+	f.Set("email", "nope")
+	if err := f.VisibleError("email"); !errors.Is(err, ErrEmail) {
+		t.Fatalf("touched email error = %v, want ErrEmail", err)
+	}
+	if err := f.VisibleError("name"); err != nil {
+		t.Fatalf("untouched name shows %v", err)
+	}
+
+	called := false
+	err := f.Submit(func(map[string]string) error { called = true; return nil })
+	if !errors.Is(err, ErrInvalid) || called {
+		t.Fatalf("Submit invalid = %v (handler called %v)", err, called)
+	}
+	if err := f.VisibleError("name"); !errors.Is(err, ErrRequired) {
+		t.Fatalf("after submit, name error = %v, want ErrRequired", err)
+	}
+	if s, _ := f.State(); s != Editing {
+		t.Fatalf("state = %v, want editing", s)
+	}
+}
+
+func TestSubmitStates(t *testing.T) {
+	f := newTestForm()
+	f.Set("name", "  Ann ")
+	f.Set("email", "ann@example.com")
+
+	var got map[string]string
+	var during State
+	if err := f.Submit(func(v map[string]string) error {
+		got = v
+		during, _ = f.State()
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if got["name"] != "Ann" {
+		t.Errorf("handler got name %q, want trimmed", got["name"])
+	}
+	if during != Submitting {
+		t.Errorf("state during handler = %v", during)
+	}
+	if s, _ := f.State(); s != Submitted {
+		t.Errorf("state after = %v", s)
+	}
+
+	boom := errors.New("server down")
+	if err := f.Submit(func(map[string]string) error { return boom }); err != boom {
+		t.Fatalf("Submit = %v", err)
+	}
+	if s, err := f.State(); s != Failed || err != boom {
+		t.Fatalf("state = %v, %v", s, err)
+	}
+
+	f.Set("name", "Bob")
+	if s, _ := f.State(); s != Editing {
+		t.Fatalf("editing after failure left state %v", s)
+	}
+
+	f.Reset()
+	if f.Value("name") != "" || f.VisibleError("name") != nil {
+		t.Fatal("Reset left values or visible errors")
+	}
+}
+
+func TestConcurrentSubmitRunsHandlerOnce(t *testing.T) {
+	f := newTestForm()
+	f.Set("name", "Ann")
+	f.Set("email", "ann@example.com")
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	handler := func(map[string]string) error {
+		calls.Add(1)
+		<-release
+		return nil
+	}
+
+	const n = 8
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() { errs <- f.Submit(handler) }()
+	}
+	for i := 0; i < n-1; i++ {
+		if err := <-errs; !errors.Is(err, ErrInProgress) {
+			t.Fatalf("concurrent Submit = %v, want ErrInProgress", err)
+		}
+	}
+	close(release)
+	if err := <-errs; err != nil {
+		t.Fatalf("winning Submit = %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("handler ran %d times", calls.Load())
+	}
+}
+
+func TestInvalidSubmitKeepsPreviousState(t *testing.T) {
+	f := newTestForm()
+	f.Set("name", "Ann")
+	f.Set("email", "ann@example.com")
+	boom := errors.New("server down")
+	f.Submit(func(map[string]string) error { return boom })
+
+	f.mu.Lock()
+	f.values["email"] = "broken" // bypass Set, which would reset the state
+	f.mu.Unlock()
+	if err := f.Submit(func(map[string]string) error { return nil }); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Submit = %v, want ErrInvalid", err)
+	}
+	if s, err := f.State(); s != Failed || err != boom {
+		t.Fatalf("state = %v, %v; want the earlier failure", s, err)
+	}
+}
+
+func TestSetUnknownField(t *testing.T) {
+	if err := newTestForm().Set("age", "3"); !errors.Is(err, ErrUnknownField) {
+		t.Fatalf("Set unknown = %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package form
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator checks one field value and returns a message-bearing error
+// suitable for showing next to the field.
+type Validator func(value string) error
+
+var (
+	ErrRequired = errors.New("this field is required")
+	ErrEmail    = errors.New("enter a valid email address")
+)
+
+// Required rejects empty or whitespace-only values.
+func Required() Validator {
+	return func(v string) error {
+		if strings.TrimSpace(v) == "" {
+			return ErrRequired
+		}
+		return nil
+	}
+}
+
+// MinLength rejects values shorter than n characters, not counting
+// surrounding whitespace, which Submit trims. Empty values pass so
+// optional fields can still be left blank; combine with Required.
+func MinLength(n int) Validator {
+	return func(v string) error {
+		v = strings.TrimSpace(v)
+		if v != "" && utf8.RuneCountInString(v) < n {
+			return fmt.Errorf("must be at least %d characters", n)
+		}
+		return nil
+	}
+}
+
+// MaxLength rejects values longer than n characters, not counting
+// surrounding whitespace.
+func MaxLength(n int) Validator {
+	return func(v string) error {
+		if utf8.RuneCountInString(strings.TrimSpace(v)) > n {
+			return fmt.Errorf("must be at most %d characters", n)
+		}
+		return nil
+	}
+}
+
+// Email accepts a bare address such as "ann@example.com". Display-name
+// forms like "Ann <ann@example.com>" are rejected, as is an address
+// without a dot in the domain. Empty values pass; combine with Required.
+func Email() Validator {
+	return func(v string) error {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return nil
+		}
+		addr, err := mail.ParseAddress(v)
+		if err != nil || addr.Name != "" || addr.Address != v {
+			return ErrEmail
+		}
+		at := strings.LastIndexByte(v, '@')
+		if domain := v[at+1:]; !strings.Contains(domain, ".") || strings.HasSuffix(domain, ".") {
+			return ErrEmail
+		}
+		return nil
+	}
+}
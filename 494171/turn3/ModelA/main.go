@@ -0,0 +1,53 @@
+package main
+
+import (
+	"log"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/app"
+
+	"ModelA/form"
+)
+
+// contactFields is the form definition; the window is generated from it.
+func contactFields() []form.Field {
+	return []form.Field{
+		{
+			Name:        "name",
+			Label:       "Name",
+			Placeholder: "Jane Doe",
+			Validators:  []form.Validator{form.Required(), form.MinLength(2), form.MaxLength(80)},
+		},
+		{
+			Name:        "email",
+			Label:       "Email",
+			Placeholder: "jane@example.com",
+			Kind:        form.EmailAddress,
+			Validators:  []form.Validator{form.Required(), form.Email()},
+		},
+		{
+			Name:        "message",
+			Label:       "Message",
+			Placeholder: "Optional, up to 500 characters",
+			Kind:        form.MultiLine,
+			Validators:  []form.Validator{form.MaxLength(500)},
+		},
+	}
+}
+
+func submitForm(values map[string]string) error {
+	// Simulate sending the form somewhere.
+	log.Printf("form submitted: %v", values)
+	return nil
+}
+
+func main() {
+	myApp := app.New()
+	mainWindow := myApp.NewWindow("Form Example")
+
+	view := newFormView(form.New(contactFields()...), mainWindow, submitForm)
+	mainWindow.SetContent(view.content)
+	mainWindow.Resize(fyne.NewSize(420, 0))
+
+	mainWindow.ShowAndRun()
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"sync"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"ModelA/form"
+)
+
+// formView renders a form.Form as fyne widgets. It holds no form state of
+// its own: input goes straight to the model and every refresh redraws from
+// it.
+type formView struct {
+	form     *form.Form
+	window   fyne.Window
+	onSubmit func(values map[string]string) error
+
+	entries map[string]*widget.Entry
+	errors  map[string]*widget.Label
+	submit  *widget.Button
+	status  *widget.Label
+	content fyne.CanvasObject
+
+	// uiMu serializes refresh and report. Submissions finish on a worker
+	// goroutine, and fyne 2.5 has no call to hand work back to the UI
+	// goroutine (fyne.Do arrived in 2.6). Its widgets lock their own
+	// state, so holding uiMu keeps a redraw from interleaving with
+	// another.
+	uiMu sync.Mutex
+	// inFlight counts running submissions; tests wait on it.
+	inFlight sync.WaitGroup
+}
+
+func newFormView(f *form.Form, w fyne.Window, onSubmit func(map[string]string) error) *formView {
+	v := &formView{
+		form:     f,
+		window:   w,
+		onSubmit: onSubmit,
+		entries:  make(map[string]*widget.Entry),
+		errors:   make(map[string]*widget.Label),
+		status:   widget.NewLabel(""),
+	}
+
+	rows := container.NewVBox()
+	for _, fd := range f.Fields() {
+		name := fd.Name
+		entry := newEntry(fd.Kind)
+		entry.SetPlaceHolder(fd.Placeholder)
+		entry.OnChanged = func(s string) {
+			// refresh copying the model into the entry fires OnChanged
+			// too; only a value the model doesn't have yet is a user edit.
+			if s != f.Value(name) {
+				f.Set(name, s)
+			}
+		}
+
+		errLabel := widget.NewLabel("")
+		errLabel.Importance = widget.DangerImportance
+		errLabel.Hide()
+
+		v.entries[name] = entry
+		v.errors[name] = errLabel
+		rows.Add(widget.NewLabelWithStyle(fd.Label, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		rows.Add(entry)
+		rows.Add(errLabel)
+	}
+
+	v.submit = widget.NewButton("Submit", v.doSubmit)
+	v.submit.Importance = widget.HighImportance
+	reset := widget.NewButton("Reset", f.Reset)
+
+	v.content = container.NewVBox(rows, container.NewHBox(v.submit, reset), v.status)
+	f.OnChange(v.refresh)
+	v.refresh()
+	return v
+}
+
+func newEntry(kind form.Kind) *widget.Entry {
+	switch kind {
+	case form.Password:
+		return widget.NewPasswordEntry()
+	case form.MultiLine:
+		return widget.NewMultiLineEntry()
+	}
+	return widget.NewEntry()
+}
+
+// refresh redraws every widget from the model.
+func (v *formView) refresh() {
+	v.uiMu.Lock()
+	defer v.uiMu.Unlock()
+
+	for name, entry := range v.entries {
+		if val := v.form.Value(name); entry.Text != val {
+			entry.SetText(val)
+		}
+		label := v.errors[name]
+		if err := v.form.VisibleError(name); err != nil {
+			label.SetText(err.Error())
+			label.Show()
+		} else {
+			label.SetText("")
+			label.Hide()
+		}
+	}
+
+	state, err := v.form.State()
+	switch state {
+	case form.Submitting:
+		v.submit.Disable()
+		v.status.SetText("Submitting…")
+	case form.Submitted:
+		v.submit.Enable()
+		v.status.SetText("Submitted.")
+	case form.Failed:
+		v.submit.Enable()
+		v.status.SetText("Submission failed: " + err.Error())
+	default:
+		v.submit.Enable()
+		v.status.SetText("")
+	}
+}
+
+// doSubmit runs the submission off the UI goroutine so a slow handler
+// doesn't freeze the window; the model's Submitting state disables the
+// button meanwhile. The redraws it triggers go through uiMu.
+func (v *formView) doSubmit() {
+	v.inFlight.Add(1)
+	go func() {
+		defer v.inFlight.Done()
+		v.report(v.form.Submit(v.onSubmit))
+	}()
+}
+
+func (v *formView) report(err error) {
+	v.uiMu.Lock()
+	defer v.uiMu.Unlock()
+	switch {
+	case err == nil:
+		dialog.ShowInformation("Form Submitted", "Your form has been successfully submitted.", v.window)
+	case errors.Is(err, form.ErrInvalid):
+		// The inline errors already say what's wrong.
+		v.status.SetText("Please fix the highlighted fields.")
+	case errors.Is(err, form.ErrInProgress):
+	default:
+		dialog.ShowError(err, v.window)
+	}
+}
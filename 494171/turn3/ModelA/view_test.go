@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+
+	"ModelA/form"
+)
+
+// newTestView builds the generated window on fyne's headless test driver.
+func newTestView(t *testing.T, onSubmit func(map[string]string) error) *formView {
+	t.Helper()
+	a := test.NewApp()
+	t.Cleanup(a.Quit)
+	w := test.NewWindow(nil)
+	t.Cleanup(w.Close)
+
+	v := newFormView(form.New(contactFields()...), w, onSubmit)
+	w.SetContent(v.content)
+	return v
+}
+
+func TestViewInlineErrors(t *testing.T) {
+	v := newTestView(t, func(map[string]string) error { return nil })
+
+	for name, label := range v.errors {
+		if label.Visible() {
+			t.Fatalf("%s error visible on a fresh form: %q", name, label.Text)
+		}
+	}
+
+	test.Type(v.entries["email"], "not-an-email")
+	if l := v.errors["email"]; !l.Visible() || l.Text != form.ErrEmail.Error() {
+		t.Fatalf("email error = %q (visible %v)", l.Text, l.Visible())
+	}
+	if v.errors["name"].Visible() {
+		t.Fatal("name error shown before the name field was touched")
+	}
+
+	test.Tap(v.submit)
+	v.inFlight.Wait()
+	if l := v.errors["name"]; !l.Visible() || l.Text != form.ErrRequired.Error() {
+		t.Fatalf("after submit, name error = %q (visible %v)", l.Text, l.Visible())
+	}
+}
+
+func TestViewSubmit(t *testing.T) {
+	var got map[string]string
+	v := newTestView(t, func(values map[string]string) error {
+		got = values
+		return nil
+	})
+
+	test.Type(v.entries["name"], "Ann")
+	test.Type(v.entries["email"], "ann@example.com")
+	test.Tap(v.submit)
+	v.inFlight.Wait()
+
+	if got["name"] != "Ann" || got["email"] != "ann@example.com" {
+		t.Fatalf("submitted %v", got)
+	}
+	if v.status.Text != "Submitted." {
+		t.Fatalf("status = %q", v.status.Text)
+	}
+	for name, label := range v.errors {
+		if label.Visible() {
+			t.Errorf("%s error visible after valid submit: %q", name, label.Text)
+		}
+	}
+}
+
+func TestViewSubmitFailureAndReset(t *testing.T) {
+	v := newTestView(t, func(map[string]string) error { return errors.New("server down") })
+
+	test.Type(v.entries["name"], "Ann")
+	test.Type(v.entries["email"], "ann@example.com")
+	test.Tap(v.submit)
+	v.inFlight.Wait()
+	if v.status.Text != "Submission failed: server down" {
+		t.Fatalf("status = %q", v.status.Text)
+	}
+
+	v.form.Reset()
+	if v.entries["name"].Text != "" || v.errors["name"].Visible() {
+		t.Fatalf("after reset: name %q, error visible %v", v.entries["name"].Text, v.errors["name"].Visible())
+	}
+}
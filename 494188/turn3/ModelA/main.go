@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// slowReader simulates a slow device by sleeping before every read.
+type slowReader struct {
+	r     io.Reader
+	delay time.Duration
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(s.delay)
+	return s.r.Read(p)
+}
+
+func report(res FileResult) {
+	var te *TimeoutError
+	switch {
+	case errors.As(res.Err, &te):
+		fmt.Printf("%-14s partial: %d bytes (timeout=%v)\n", filepath.Base(res.Path), len(res.Data), te.Timeout())
+	case res.Err != nil:
+		fmt.Printf("%-14s error: %v\n", filepath.Base(res.Path), res.Err)
+	default:
+		fmt.Printf("%-14s complete: %d bytes\n", filepath.Base(res.Path), len(res.Data))
+	}
+}
+
+func main() {
+	// A single file, as before but without the leaking goroutine.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	data, err := ReadFile(ctx, "example.txt", Options{})
+	cancel()
+	if err != nil {
+		log.Fatal("Error reading file: ", err)
+	}
+	fmt.Printf("Data received: %q\n\n", data)
+
+	// A slow reader that can't finish before the deadline.
+	ctx, cancel = context.WithTimeout(context.Background(), 300*time.Millisecond)
+	slow := &slowReader{r: bytes.NewReader(make([]byte, 1<<20)), delay: 50 * time.Millisecond}
+	data, err = ReadContext(ctx, "slow device", slow, 1<<20, Options{ChunkSize: 64 << 10})
+	cancel()
+	fmt.Printf("slow device: got %d bytes, err: %v\n\n", len(data), err)
+
+	// Several files under one overall deadline, with progress.
+	dir, err := os.MkdirTemp("", "ctxread")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var paths []string
+	for i, size := range []int{4 << 10, 2 << 20, 64 << 20} {
+		p := filepath.Join(dir, fmt.Sprintf("file%d.bin", i+1))
+		if err := os.WriteFile(p, make([]byte, size), 0644); err != nil {
+			log.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+	paths = append(paths, filepath.Join(dir, "missing.bin"))
+
+	var mu sync.Mutex
+	lastPct := map[string]int64{}
+	opts := Options{
+		ChunkSize: 256 << 10,
+		OnProgress: func(p Progress) {
+			if p.Total <= 0 {
+				// Size unknown (or an empty file): no percentage to show.
+				return
+			}
+			pct := p.Read * 100 / p.Total
+			mu.Lock()
+			defer mu.Unlock()
+			if pct/25 > lastPct[p.Path]/25 {
+				fmt.Printf("  %s: %d%%\n", filepath.Base(p.Path), pct)
+			}
+			lastPct[p.Path] = pct
+		},
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	for _, res := range ReadFiles(ctx, paths, opts) {
+		report(res)
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultChunkSize = 32 << 10
+
+// Progress is reported after every chunk. Total is -1 when the size isn't
+// known up front.
+type Progress struct {
+	Path  string
+	Read  int64
+	Total int64
+}
+
+// Options configures a read. The zero value is usable.
+type Options struct {
+	ChunkSize int
+	// OnProgress is called after each chunk. ReadFiles calls it from
+	// several goroutines at once, so it must be safe for concurrent use.
+	OnProgress func(Progress)
+}
+
+// TimeoutError is returned when the context ends before the read
+// finishes. The bytes read so far are returned alongside it.
+type TimeoutError struct {
+	Path string
+	Read int64
+	Err  error // context.DeadlineExceeded or context.Canceled
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("reading %s: stopped after %d bytes: %v", e.Path, e.Read, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Timeout reports whether the read hit a deadline rather than being
+// canceled, for callers that check net.Error-style interfaces.
+func (e *TimeoutError) Timeout() bool { return errors.Is(e.Err, context.DeadlineExceeded) }
+
+// deadliner is implemented by readers whose blocking Read can be
+// interrupted, such as pipes and network connections.
+type deadliner interface {
+	SetReadDeadline(time.Time) error
+}
+
+// ReadContext reads r in chunks until EOF or until ctx is done. ctx is
+// checked between chunks, so a regular file stops within one chunk; for
+// readers that support read deadlines a blocked Read is also interrupted.
+// On cancellation it returns the data read so far and a *TimeoutError.
+func ReadContext(ctx context.Context, name string, r io.Reader, total int64, opts Options) ([]byte, error) {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+
+	if d, ok := r.(deadliner); ok {
+		if dl, ok := ctx.Deadline(); ok {
+			d.SetReadDeadline(dl)
+		}
+		// Wake a blocked Read on cancel too. Errors are ignored: regular
+		// files report that they don't support deadlines.
+		stop := context.AfterFunc(ctx, func() { d.SetReadDeadline(time.Now()) })
+		defer stop()
+	}
+
+	var data []byte
+	if total > 0 {
+		data = make([]byte, 0, total)
+	}
+	buf := make([]byte, chunkSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return data, &TimeoutError{Path: name, Read: int64(len(data)), Err: err}
+		}
+		n, err := r.Read(buf)
+		data = append(data, buf[:n]...)
+		if n > 0 && opts.OnProgress != nil {
+			opts.OnProgress(Progress{Path: name, Read: int64(len(data)), Total: total})
+		}
+		if err == io.EOF {
+			return data, nil
+		}
+		if err != nil {
+			// A deadline we set ourselves shows up as a read error;
+			// report it as the context's error. The fd deadline can fire
+			// a moment before ctx's own timer, while ctx.Err is still
+			// nil, so it counts as ctx's deadline either way.
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return data, &TimeoutError{Path: name, Read: int64(len(data)), Err: ctxErr}
+			}
+			if _, ok := ctx.Deadline(); ok && errors.Is(err, os.ErrDeadlineExceeded) {
+				return data, &TimeoutError{Path: name, Read: int64(len(data)), Err: context.DeadlineExceeded}
+			}
+			return data, fmt.Errorf("reading %s: %w", name, err)
+		}
+	}
+}
+
+// ReadFile reads the file at path with ReadContext.
+func ReadFile(ctx context.Context, path string, opts Options) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	total := int64(-1)
+	if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+		total = fi.Size()
+	}
+	return ReadContext(ctx, path, f, total, opts)
+}
+
+// FileResult is the outcome for one file of ReadFiles. Data holds whatever
+// was read, even when Err is set.
+type FileResult struct {
+	Path string
+	Data []byte
+	Err  error
+}
+
+// ReadFiles reads every path concurrently under ctx, so one deadline
+// covers them all. Results are in the same order as paths.
+func ReadFiles(ctx context.Context, paths []string, opts Options) []FileResult {
+	results := make([]FileResult, len(paths))
+	var wg sync.WaitGroup
+	for i, p := range paths {
+		wg.Add(1)
+		go func(i int, p string) {
+			defer wg.Done()
+			data, err := ReadFile(ctx, p, opts)
+			results[i] = FileResult{Path: p, Data: data, Err: err}
+		}(i, p)
+	}
+	wg.Wait()
+	return results
+}
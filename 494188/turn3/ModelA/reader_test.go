@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReadContextComplete(t *testing.T) {
+	src := bytes.Repeat([]byte("0123456789"), 1000)
+	var progress []Progress
+	opts := Options{ChunkSize: 4096, OnProgress: func(p Progress) { progress = append(progress, p) }}
+
+	data, err := ReadContext(context.Background(), "mem", bytes.NewReader(src), int64(len(src)), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, src) {
+		t.Fatalf("got %d bytes, want %d", len(data), len(src))
+	}
+	if len(progress) != 3 {
+		t.Fatalf("got %d progress reports, want 3", len(progress))
+	}
+	if last := progress[len(progress)-1]; last.Read != int64(len(src)) || last.Total != int64(len(src)) {
+		t.Errorf("last progress = %+v", last)
+	}
+}
+
+func TestReadContextSlowReaderTimesOut(t *testing.T) {
+	const size = 1 << 20
+	slow := &slowReader{r: bytes.NewReader(make([]byte, size)), delay: 20 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	data, err := ReadContext(ctx, "slow", slow, size, Options{ChunkSize: 4096})
+	var te *TimeoutError
+	if !errors.As(err, &te) {
+		t.Fatalf("err = %v, want *TimeoutError", err)
+	}
+	if !te.Timeout() || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want a deadline timeout", err)
+	}
+	if len(data) == 0 || len(data) >= size {
+		t.Errorf("got %d bytes, want a partial read", len(data))
+	}
+	if te.Read != int64(len(data)) {
+		t.Errorf("TimeoutError.Read = %d, returned %d bytes", te.Read, len(data))
+	}
+}
+
+// TestReadContextInterruptsBlockedPipe checks that a Read blocked on a pipe
+// with no writer activity is woken by cancellation, not just by the next
+// chunk boundary.
+func TestReadContextInterruptsBlockedPipe(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if _, err := w.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	data, err := ReadContext(ctx, "pipe", r, -1, Options{})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("ReadContext took %s to notice cancellation", elapsed)
+	}
+	var te *TimeoutError
+	if !errors.As(err, &te) || te.Timeout() || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want a canceled *TimeoutError", err)
+	}
+	if string(data) != "hello" {
+		t.Errorf("data = %q, want the bytes written before cancel", data)
+	}
+}
+
+func TestReadContextPipeDeadline(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = ReadContext(ctx, "pipe", r, -1, Options{})
+	var te *TimeoutError
+	if !errors.As(err, &te) || !te.Timeout() {
+		t.Fatalf("err = %v, want a deadline *TimeoutError", err)
+	}
+}
@@ -0,0 +1,68 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestReadFilesSharedDeadline reads a regular file, a missing file and a
+// FIFO whose writer never finishes under one deadline: the regular file
+// completes, the FIFO stops at the deadline with what it got, and the
+// missing file fails on its own.
+func TestReadFilesSharedDeadline(t *testing.T) {
+	dir := t.TempDir()
+	small := filepath.Join(dir, "small.txt")
+	if err := os.WriteFile(small, []byte("done"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fifo := filepath.Join(dir, "stalled.fifo")
+	if err := syscall.Mkfifo(fifo, 0644); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	// Opening a FIFO blocks until both ends are open, so the writer
+	// opens it in the background and then stalls after one write.
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		w, err := os.OpenFile(fifo, os.O_WRONLY, 0)
+		if err != nil {
+			return
+		}
+		defer w.Close()
+		w.Write([]byte("part"))
+		time.Sleep(500 * time.Millisecond)
+	}()
+	missing := filepath.Join(dir, "missing.txt")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	results := ReadFiles(ctx, []string{small, fifo, missing}, Options{})
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("ReadFiles took %s, want it bounded by the shared deadline", elapsed)
+	}
+
+	if r := results[0]; r.Err != nil || string(r.Data) != "done" {
+		t.Errorf("small: %q, %v", r.Data, r.Err)
+	}
+	var te *TimeoutError
+	if r := results[1]; !errors.As(r.Err, &te) || !te.Timeout() || string(r.Data) != "part" {
+		t.Errorf("fifo: %q, %v; want partial data and a timeout", r.Data, r.Err)
+	}
+	if r := results[2]; !errors.Is(r.Err, os.ErrNotExist) {
+		t.Errorf("missing: %v", r.Err)
+	}
+	for i, p := range []string{small, fifo, missing} {
+		if results[i].Path != p {
+			t.Errorf("results[%d].Path = %s, want %s", i, results[i].Path, p)
+		}
+	}
+	<-writerDone
+}
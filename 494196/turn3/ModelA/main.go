@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+var (
+	ErrTransient = errors.New("temporarily unavailable")
+	ErrNoChange  = errors.New("nothing to update")
+)
+
+// FatalError means the process can't safely keep running.
+type FatalError struct {
+	Reason string
+}
+
+func (e *FatalError) Error() string { return "fatal: " + e.Reason }
+
+// eventLog buffers lines in memory until flushed, standing in for any
+// writer that needs an explicit flush on shutdown.
+type eventLog struct {
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func (l *eventLog) Printf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintf(l.w, format+"\n", args...)
+}
+
+func (l *eventLog) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Flush()
+}
+
+// doWork simulates an operation whose failure mode is chosen by mode.
+// "flaky" fails transiently on its first two calls.
+func doWork(mode string, calls *atomic.Int32) func(context.Context) error {
+	return func(ctx context.Context) error {
+		switch mode {
+		case "flaky":
+			if calls.Add(1) <= 2 {
+				return fmt.Errorf("backend: %w", ErrTransient)
+			}
+			return nil
+		case "down":
+			return fmt.Errorf("backend: %w", ErrTransient)
+		case "noop":
+			return ErrNoChange
+		case "fatal":
+			return &FatalError{Reason: "storage checksum mismatch"}
+		case "bad":
+			return errors.New("invalid request payload")
+		}
+		return nil
+	}
+}
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8080", "listen address")
+	demo := flag.Bool("demo", false, "send some requests to ourselves, ending with a fatal one")
+	flag.Parse()
+
+	logger := log.New(os.Stderr, "", log.LstdFlags|log.Lmicroseconds)
+	options := ShutdownOptions{
+		Timeout:     10 * time.Second,
+		HookTimeout: 3 * time.Second,
+		Signals:     []os.Signal{os.Interrupt, syscall.SIGTERM},
+		Logger:      logger,
+	}
+	shutdown := NewOrchestrator(options)
+
+	registry := NewRegistry(logger)
+	registry.OnShutdown = shutdown.Trigger
+	registry.RegisterIs(ErrNoChange, Strategy{Policy: Ignore})
+	registry.RegisterIs(ErrTransient, Strategy{Policy: Retry, MaxAttempts: 3, Backoff: 100 * time.Millisecond, MaxDelay: time.Second})
+	RegisterAs[*FatalError](registry, Strategy{Policy: Shutdown})
+
+	events := &eventLog{w: bufio.NewWriter(os.Stdout)}
+	var accepting atomic.Bool
+	accepting.Store(true)
+	var flakyCalls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if !accepting.Load() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/work", func(w http.ResponseWriter, r *http.Request) {
+		if !accepting.Load() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		mode := r.URL.Query().Get("mode")
+		err := registry.Run(r.Context(), "work:"+mode, doWork(mode, &flakyCalls))
+		events.Printf("work mode=%s err=%v", mode, err)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintln(w, "done")
+	})
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	srv := &http.Server{Handler: mux}
+	go func() {
+		if err := srv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			shutdown.Trigger(fmt.Errorf("http server: %w", err))
+		}
+	}()
+	logger.Printf("Server is running on %s", ln.Addr())
+
+	shutdown.Register(Hook{Name: "mark-unready", Phase: PhaseStopAccepting, Fn: func(ctx context.Context) error {
+		accepting.Store(false)
+		// Give load balancers a moment to see the failing health check.
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-ctx.Done():
+		}
+		return nil
+	}})
+	shutdown.Register(Hook{Name: "http-server", Phase: PhaseDrainHTTP, Timeout: 5 * time.Second, Fn: srv.Shutdown})
+	shutdown.Register(Hook{Name: "event-log", Phase: PhaseFlush, Fn: func(context.Context) error {
+		return events.Flush()
+	}})
+	shutdown.Register(Hook{Name: "database", Phase: PhaseCloseResources, Timeout: 500 * time.Millisecond, Fn: func(ctx context.Context) error {
+		// Simulate a slow close; if it overruns, the hook timeout cuts it
+		// off and shutdown carries on.
+		select {
+		case <-time.After(100 * time.Millisecond):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}})
+
+	if *demo {
+		go func() {
+			base := "http://" + ln.Addr().String() + "/work?mode="
+			for _, mode := range []string{"flaky", "noop", "bad", "down", "fatal"} {
+				resp, err := http.Get(base + mode)
+				if err != nil {
+					logger.Printf("demo %s: %v", mode, err)
+					continue
+				}
+				resp.Body.Close()
+				logger.Printf("demo %s: %s", mode, resp.Status)
+			}
+		}()
+	}
+
+	results, err := shutdown.Wait()
+	logger.Printf("Server stopped (%d hooks)", len(results))
+	code := 0
+	if err != nil {
+		logger.Printf("shutdown errors: %v", err)
+		code = 1
+	}
+	// A signal is a requested stop; anything passed to Trigger, such as
+	// a FatalError, is a failure the exit status should report.
+	var sig *SignalError
+	if reason := shutdown.Reason(); reason != nil && !errors.As(reason, &sig) {
+		code = 1
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Phase orders shutdown hooks. Lower phases run first; hooks within a
+// phase run in registration order.
+type Phase int
+
+const (
+	PhaseStopAccepting Phase = iota
+	PhaseDrainHTTP
+	PhaseFlush
+	PhaseCloseResources
+)
+
+func (p Phase) String() string {
+	switch p {
+	case PhaseStopAccepting:
+		return "stop-accepting"
+	case PhaseDrainHTTP:
+		return "drain-http"
+	case PhaseFlush:
+		return "flush"
+	case PhaseCloseResources:
+		return "close-resources"
+	}
+	return fmt.Sprintf("Phase(%d)", int(p))
+}
+
+type ShutdownOptions struct {
+	// Timeout bounds the whole shutdown.
+	Timeout time.Duration
+	// HookTimeout is the default per-hook limit, used when a Hook sets
+	// none. A hook never gets more than what's left of Timeout.
+	HookTimeout time.Duration
+	// Signals that start a shutdown. Defaults to os.Interrupt.
+	Signals []os.Signal
+	Logger  *log.Logger
+}
+
+// Hook is one shutdown step. Fn should return promptly once ctx is done;
+// if it doesn't, the orchestrator stops waiting and moves on.
+type Hook struct {
+	Name    string
+	Phase   Phase
+	Timeout time.Duration
+	Fn      func(ctx context.Context) error
+}
+
+// HookResult records how one hook went.
+type HookResult struct {
+	Name     string
+	Phase    Phase
+	Duration time.Duration
+	Err      error
+}
+
+// SignalError is the shutdown reason when a signal started the shutdown,
+// as opposed to a call to Trigger.
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string { return fmt.Sprintf("received signal %v", e.Signal) }
+
+// Orchestrator waits for a signal or a Trigger and then runs its hooks in
+// phase order.
+type Orchestrator struct {
+	opts ShutdownOptions
+
+	mu     sync.Mutex
+	hooks  []Hook
+	reason error
+
+	trigger chan error
+	once    sync.Once
+}
+
+func NewOrchestrator(opts ShutdownOptions) *Orchestrator {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.HookTimeout <= 0 {
+		opts.HookTimeout = opts.Timeout
+	}
+	if len(opts.Signals) == 0 {
+		opts.Signals = []os.Signal{os.Interrupt}
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+	return &Orchestrator{opts: opts, trigger: make(chan error, 1)}
+}
+
+func (o *Orchestrator) Register(h Hook) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.hooks = append(o.hooks, h)
+}
+
+// Trigger starts a shutdown from inside the program. Only the first call
+// has an effect.
+func (o *Orchestrator) Trigger(reason error) {
+	o.once.Do(func() { o.trigger <- reason })
+}
+
+// Wait blocks until a signal arrives or Trigger is called, then runs the
+// hooks. It returns every hook's result and the joined hook errors; Reason
+// tells why the shutdown started.
+func (o *Orchestrator) Wait() ([]HookResult, error) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, o.opts.Signals...)
+	defer signal.Stop(sigs)
+
+	var reason error
+	select {
+	case sig := <-sigs:
+		reason = &SignalError{Signal: sig}
+	case reason = <-o.trigger:
+	}
+	o.mu.Lock()
+	o.reason = reason
+	o.mu.Unlock()
+	o.opts.Logger.Printf("shutting down: %v", reason)
+	return o.run()
+}
+
+// Reason returns what started the shutdown: a *SignalError, the error
+// passed to Trigger, or nil if no shutdown has started (or Trigger was
+// given nil).
+func (o *Orchestrator) Reason() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.reason
+}
+
+func (o *Orchestrator) run() ([]HookResult, error) {
+	o.mu.Lock()
+	hooks := append([]Hook(nil), o.hooks...)
+	o.mu.Unlock()
+	sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].Phase < hooks[j].Phase })
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.opts.Timeout)
+	defer cancel()
+
+	results := make([]HookResult, 0, len(hooks))
+	var errs []error
+	for _, h := range hooks {
+		res := o.runHook(ctx, h)
+		results = append(results, res)
+		if res.Err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", h.Phase, h.Name, res.Err))
+			o.opts.Logger.Printf("shutdown %s/%s failed after %v: %v", h.Phase, h.Name, res.Duration, res.Err)
+		} else {
+			o.opts.Logger.Printf("shutdown %s/%s done in %v", h.Phase, h.Name, res.Duration)
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// runHook runs h with its own timeout. A hook that ignores its context is
+// abandoned when the timeout passes so later phases still run.
+func (o *Orchestrator) runHook(parent context.Context, h Hook) HookResult {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = o.opts.HookTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- h.Fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	return HookResult{Name: h.Name, Phase: h.Phase, Duration: time.Since(start), Err: err}
+}
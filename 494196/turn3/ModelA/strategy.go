@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+)
+
+// Policy says what to do about a failed operation.
+type Policy int
+
+const (
+	// Escalate returns the error to the caller. It is the default for
+	// errors no rule matches.
+	Escalate Policy = iota
+	// Ignore logs the error and reports success.
+	Ignore
+	// Retry re-invokes the operation with backoff.
+	Retry
+	// Shutdown asks the application to shut down and returns the error.
+	Shutdown
+)
+
+func (p Policy) String() string {
+	switch p {
+	case Escalate:
+		return "escalate"
+	case Ignore:
+		return "ignore"
+	case Retry:
+		return "retry"
+	case Shutdown:
+		return "shutdown"
+	}
+	return fmt.Sprintf("Policy(%d)", int(p))
+}
+
+// Strategy is a Policy plus its parameters.
+type Strategy struct {
+	Policy      Policy
+	MaxAttempts int           // Retry: total attempts including the first
+	Backoff     time.Duration // Retry: delay before the first retry, doubled after each
+	MaxDelay    time.Duration // Retry: cap on any one delay; 0 means no cap
+}
+
+// delay returns the wait after the given failed attempt: Backoff doubled
+// attempt-1 times, capped at MaxDelay. Doubling stops at the cap instead
+// of shifting, which would overflow into a negative or zero delay.
+func (s Strategy) delay(attempt int) time.Duration {
+	limit := s.MaxDelay
+	if limit <= 0 {
+		limit = math.MaxInt64
+	}
+	d := min(s.Backoff, limit)
+	for i := 1; i < attempt && d < limit; i++ {
+		if d > limit/2 {
+			return limit
+		}
+		d *= 2
+	}
+	return d
+}
+
+type rule struct {
+	match    func(error) bool
+	strategy Strategy
+}
+
+// Registry classifies errors into strategies and applies them. Rules are
+// tried in registration order; the first match wins.
+type Registry struct {
+	mu    sync.RWMutex
+	rules []rule
+
+	// OnShutdown is called with the error when the Shutdown policy
+	// applies, typically Orchestrator.Trigger.
+	OnShutdown func(error)
+	Logger     *log.Logger
+}
+
+func NewRegistry(logger *log.Logger) *Registry {
+	return &Registry{Logger: logger}
+}
+
+// Register adds a rule for errors matching match.
+func (r *Registry) Register(match func(error) bool, s Strategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = append(r.rules, rule{match: match, strategy: s})
+}
+
+// RegisterIs adds a rule for errors that wrap target.
+func (r *Registry) RegisterIs(target error, s Strategy) {
+	r.Register(func(err error) bool { return errors.Is(err, target) }, s)
+}
+
+// RegisterAs adds a rule for errors with an E anywhere in their chain.
+func RegisterAs[E error](r *Registry, s Strategy) {
+	r.Register(func(err error) bool {
+		var target E
+		return errors.As(err, &target)
+	}, s)
+}
+
+// Classify returns the strategy for err.
+func (r *Registry) Classify(err error) Strategy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, rl := range r.rules {
+		if rl.match(err) {
+			return rl.strategy
+		}
+	}
+	return Strategy{Policy: Escalate}
+}
+
+// OperationError is returned when an operation's error is escalated or
+// triggers a shutdown.
+type OperationError struct {
+	Op       string
+	Policy   Policy
+	Attempts int
+	Err      error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("%s failed after %d attempt(s) (%s): %v", e.Op, e.Attempts, e.Policy, e.Err)
+}
+
+func (e *OperationError) Unwrap() error { return e.Err }
+
+// Run calls op and handles its error according to the registry. Each new
+// error is classified afresh, so an operation that starts out retryable
+// and then fails differently is handled by the second error's policy.
+// Retries stop early if ctx is done.
+func (r *Registry) Run(ctx context.Context, name string, op func(context.Context) error) error {
+	attempt := 0
+	for {
+		attempt++
+		err := op(ctx)
+		if err == nil {
+			return nil
+		}
+
+		s := r.Classify(err)
+		switch s.Policy {
+		case Ignore:
+			r.logf("%s: ignoring error: %v", name, err)
+			return nil
+
+		case Retry:
+			if attempt < s.MaxAttempts {
+				delay := s.delay(attempt)
+				r.logf("%s: attempt %d/%d failed, retrying in %v: %v", name, attempt, s.MaxAttempts, delay, err)
+				t := time.NewTimer(delay)
+				select {
+				case <-t.C:
+					continue
+				case <-ctx.Done():
+					t.Stop()
+					return &OperationError{Op: name, Policy: Retry, Attempts: attempt, Err: errors.Join(err, ctx.Err())}
+				}
+			}
+			return &OperationError{Op: name, Policy: Retry, Attempts: attempt, Err: err}
+
+		case Shutdown:
+			r.logf("%s: fatal error, requesting shutdown: %v", name, err)
+			if r.OnShutdown != nil {
+				r.OnShutdown(err)
+			}
+			return &OperationError{Op: name, Policy: Shutdown, Attempts: attempt, Err: err}
+
+		default:
+			return &OperationError{Op: name, Policy: Escalate, Attempts: attempt, Err: err}
+		}
+	}
+}
+
+func (r *Registry) logf(format string, args ...interface{}) {
+	if r.Logger != nil {
+		r.Logger.Printf(format, args...)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrorKind classifies a pipeline failure for the client.
+type ErrorKind int
+
+const (
+	KindInternal ErrorKind = iota
+	KindDecode
+	KindValidation
+	KindTooLarge
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindDecode:
+		return "decode"
+	case KindValidation:
+		return "validation"
+	case KindTooLarge:
+		return "too_large"
+	}
+	return "internal"
+}
+
+func (k ErrorKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// FieldError is one invalid field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors lets a validation stage report every bad field at once.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	parts := make([]string, len(v))
+	for i, fe := range v {
+		parts[i] = fe.Field + ": " + fe.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// PipelineError is a failure in one stage. It serializes as the error
+// response body.
+type PipelineError struct {
+	Stage   string       `json:"stage"`
+	Kind    ErrorKind    `json:"kind"`
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+	Err     error        `json:"-"`
+}
+
+func (e *PipelineError) Error() string {
+	return fmt.Sprintf("%s stage failed (%s): %s", e.Stage, e.Kind, e.Message)
+}
+
+func (e *PipelineError) Unwrap() error { return e.Err }
+
+// StatusCode maps the error kind to an HTTP status.
+func (e *PipelineError) StatusCode() int {
+	switch e.Kind {
+	case KindDecode:
+		return http.StatusBadRequest
+	case KindValidation:
+		return http.StatusUnprocessableEntity
+	case KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const maxBodyBytes = 1 << 20
+
+type UserData struct {
+	Name string `json:"name"`
+}
+
+type ProcessedData struct {
+	Greeting string `json:"greeting"`
+}
+
+// readBody reads the body in chunks, checking ctx between them, so the
+// stage timeout stops a client that trickles its upload. A single Read
+// that blocks is still bounded only by the server's ReadTimeout.
+func readBody(ctx context.Context, r *http.Request) ([]byte, error) {
+	var body []byte
+	buf := make([]byte, 32<<10)
+	for {
+		if err := ctx.Err(); err != nil {
+			return body, err
+		}
+		n, err := r.Body.Read(buf)
+		body = append(body, buf[:n]...)
+		if err == io.EOF {
+			return body, nil
+		}
+		if err != nil {
+			return body, err
+		}
+	}
+}
+
+func decodeUser(ctx context.Context, body []byte) (UserData, error) {
+	var data UserData
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&data); err != nil {
+		return data, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return data, errors.New("invalid JSON: trailing data after object")
+	}
+	return data, nil
+}
+
+func validateData(ctx context.Context, data UserData) (UserData, error) {
+	var errs ValidationErrors
+	name := strings.TrimSpace(data.Name)
+	switch {
+	case name == "":
+		errs = append(errs, FieldError{Field: "name", Message: "is required"})
+	case utf8.RuneCountInString(name) > 64:
+		errs = append(errs, FieldError{Field: "name", Message: "must be at most 64 characters"})
+	}
+	if len(errs) > 0 {
+		return data, errs
+	}
+	data.Name = name
+	return data, nil
+}
+
+func processData(ctx context.Context, data UserData) (ProcessedData, error) {
+	// Simulate a slow dependency for one name so the stage timeout can be
+	// seen in action.
+	if data.Name == "slow" {
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return ProcessedData{}, ctx.Err()
+		}
+	}
+	return ProcessedData{Greeting: fmt.Sprintf("Hello, %s!", data.Name)}, nil
+}
+
+// processPipeline is read -> decode -> validate -> process.
+var processPipeline = Then(Then(Then(
+	From(Stage[*http.Request, []byte]{Name: "read", Kind: KindDecode, Timeout: 5 * time.Second, Fn: readBody}),
+	Stage[[]byte, UserData]{Name: "decode", Kind: KindDecode, Fn: decodeUser}),
+	Stage[UserData, UserData]{Name: "validate", Kind: KindValidation, Fn: validateData}),
+	Stage[UserData, ProcessedData]{Name: "process", Timeout: 200 * time.Millisecond, Fn: processData},
+)
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("write response: %v", err)
+	}
+}
+
+func handleError(w http.ResponseWriter, err error) {
+	var pe *PipelineError
+	if !errors.As(err, &pe) {
+		pe = &PipelineError{Stage: "unknown", Kind: KindInternal, Message: err.Error(), Err: err}
+	}
+	status := pe.StatusCode()
+	if status >= 500 {
+		// Keep internals out of the response; the log has the detail.
+		log.Printf("internal error: %v", pe)
+		pe = &PipelineError{Stage: pe.Stage, Kind: pe.Kind, Message: "internal error"}
+	}
+	writeJSON(w, status, map[string]*PipelineError{"error": pe})
+}
+
+// serverTiming renders spans as a Server-Timing header value.
+func serverTiming(spans []Span) string {
+	parts := make([]string, len(spans))
+	for i, s := range spans {
+		parts[i] = fmt.Sprintf("%s;dur=%.3f", s.Stage, float64(s.Duration.Microseconds())/1000)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func processHandler(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	ctx, trace := WithTrace(r.Context())
+
+	out, err := processPipeline.Run(ctx, r)
+
+	spans := trace.Spans()
+	w.Header().Set("Server-Timing", serverTiming(spans))
+	for _, s := range spans {
+		log.Printf("stage=%s dur=%v err=%v", s.Stage, s.Duration, s.Err)
+	}
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func main() {
+	router := http.NewServeMux()
+	router.HandleFunc("POST /process", processHandler)
+
+	log.Println("Server running on :8080")
+	log.Fatal(http.ListenAndServe(":8080", router))
+}
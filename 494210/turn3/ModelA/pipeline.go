@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// StageFunc is the work of one stage.
+type StageFunc[In, Out any] func(ctx context.Context, in In) (Out, error)
+
+// Stage is a named step of a pipeline. Kind classifies the errors it
+// returns. If Timeout is set the stage's context gets that deadline;
+// stages are expected to honor their context.
+type Stage[In, Out any] struct {
+	Name    string
+	Kind    ErrorKind
+	Timeout time.Duration
+	Fn      StageFunc[In, Out]
+}
+
+// Pipeline runs a chain of stages, feeding each one's output to the next.
+// Build one with From and extend it with Then; the zero value is not
+// usable.
+type Pipeline[In, Out any] struct {
+	run func(ctx context.Context, in In) (Out, error)
+}
+
+// From starts a pipeline with a single stage.
+func From[In, Out any](s Stage[In, Out]) Pipeline[In, Out] {
+	return Pipeline[In, Out]{run: func(ctx context.Context, in In) (Out, error) {
+		return runStage(ctx, s, in)
+	}}
+}
+
+// Then returns a pipeline that runs p and then s on its output. It is a
+// function rather than a method because methods can't introduce the new
+// type parameter Out.
+func Then[In, Mid, Out any](p Pipeline[In, Mid], s Stage[Mid, Out]) Pipeline[In, Out] {
+	return Pipeline[In, Out]{run: func(ctx context.Context, in In) (Out, error) {
+		mid, err := p.run(ctx, in)
+		if err != nil {
+			var zero Out
+			return zero, err
+		}
+		return runStage(ctx, s, mid)
+	}}
+}
+
+// Run executes the pipeline. Any failure is returned as a *PipelineError
+// naming the stage that failed.
+func (p Pipeline[In, Out]) Run(ctx context.Context, in In) (Out, error) {
+	return p.run(ctx, in)
+}
+
+func runStage[In, Out any](ctx context.Context, s Stage[In, Out], in In) (Out, error) {
+	if err := ctx.Err(); err != nil {
+		var zero Out
+		return zero, &PipelineError{Stage: s.Name, Kind: KindInternal, Message: "request canceled", Err: err}
+	}
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	out, err := s.Fn(ctx, in)
+	if t := traceFrom(ctx); t != nil {
+		t.add(Span{Stage: s.Name, Start: start, Duration: time.Since(start), Err: err})
+	}
+	if err == nil {
+		return out, nil
+	}
+
+	var zero Out
+	var pe *PipelineError
+	if errors.As(err, &pe) {
+		return zero, err
+	}
+	pe = &PipelineError{Stage: s.Name, Kind: s.Kind, Message: err.Error(), Err: err}
+	// A timeout is our failure, not the client's, whatever the stage.
+	if errors.Is(err, context.DeadlineExceeded) {
+		pe.Kind, pe.Message = KindInternal, "stage timed out"
+	}
+	// Likewise a body over http.MaxBytesReader's limit is always 413.
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		pe.Kind, pe.Message = KindTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)
+	}
+	var ve ValidationErrors
+	if errors.As(err, &ve) {
+		pe.Details = ve
+	}
+	return zero, pe
+}
+
+// Span records one stage execution.
+type Span struct {
+	Stage    string
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// Trace collects the spans of one pipeline run.
+type Trace struct {
+	mu    sync.Mutex
+	spans []Span
+}
+
+func (t *Trace) add(s Span) {
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+}
+
+// Spans returns the recorded spans in execution order.
+func (t *Trace) Spans() []Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Span(nil), t.spans...)
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context under which every stage records a Span into
+// the returned Trace.
+func WithTrace(ctx context.Context) (context.Context, *Trace) {
+	t := &Trace{}
+	return context.WithValue(ctx, traceKey{}, t), t
+}
+
+func traceFrom(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}